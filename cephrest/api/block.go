@@ -0,0 +1,309 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+)
+
+// ImageCreate implements struct send to ceph for rbd image creation on POST /api/block/image.
+// Unlike ceph.RBDCreate the configuration (rbd_qos_* etc.) can be set.
+// --> https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image
+type ImageCreate struct {
+	Features      []string               `json:"features"`
+	PoolName      string                 `json:"pool_name"`
+	Namespace     *string                `json:"namespace"`
+	Name          string                 `json:"name"`
+	Size          int64                  `json:"size"`
+	ObjSize       int                    `json:"obj_size"`
+	StripeUnit    interface{}            `json:"stripe_unit"`
+	StripeCount   interface{}            `json:"stripe_count"`
+	DataPool      interface{}            `json:"data_pool"`
+	Configuration map[string]interface{} `json:"configuration"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ImageUpdate implements struct send to ceph for rbd image updates on PUT /api/block/image/{image_spec}.
+// Features, Configuration and Metadata are left untouched by ceph if nil, a configuration or
+// metadata value of nil removes the option or key from the image.
+// --> https://docs.ceph.com/en/latest/mgr/ceph_api/#put--api-block-image-image_spec
+type ImageUpdate struct {
+	Features      []string               `json:"features"`
+	Name          string                 `json:"name"`
+	Size          int64                  `json:"size"`
+	Configuration map[string]interface{} `json:"configuration"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ListBlockImage lists the RBD images of pool poolName (all pools if empty)
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-image).
+// Unlike ceph.Client.ListBlockImage the returned error wraps ErrNotFound etc.
+func (c *Client) ListBlockImage(poolName string) (status int, rbdList ceph.RBDList, err error) {
+	req := c.newRequest().SetResult(&rbdList)
+
+	if poolName != "" {
+		req.SetQueryParam("pool_name", poolName)
+	}
+
+	resp, err := req.Get(c.getURL("block/image"))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not list images of pool '%s'", poolName)); err != nil {
+		return statusCode(resp), nil, err
+	}
+
+	return resp.StatusCode(), rbdList, nil
+}
+
+// GetBlockImage gets an RBD block image (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-image-image_spec).
+// Unlike ceph.Client.GetBlockImage the returned error wraps ErrNotFound etc.
+func (c *Client) GetBlockImage(imageSpec string) (status int, rbd Image, err error) {
+	if imageSpec == "" {
+		return 0, rbd, ceph.ErrImageSpecIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetResult(&rbd).
+		Get(c.getURL(fmt.Sprintf("block/image/%s", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not get image %s", imageSpec)); err != nil {
+		return statusCode(resp), rbd, err
+	}
+
+	return resp.StatusCode(), rbd, nil
+}
+
+// CreateBlockImage creates an RBD image and waits for the rbd/create task
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image).
+func (c *Client) CreateBlockImage(imageCreate ImageCreate) (status int, err error) {
+	if imageCreate.PoolName == "" {
+		return 0, ceph.ErrPoolNameIsEmpty
+	}
+
+	if imageCreate.Name == "" {
+		return 0, ceph.ErrImageNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(imageCreate).
+		Post(c.getURL("block/image"))
+
+	imageSpec := ceph.PathJoin(imageCreate.PoolName, imageCreate.Namespace, imageCreate.Name)
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not create image %s", imageSpec)); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return statusCode(resp), fmt.Errorf("%w: %v", ceph.ErrCreateImageAlreadyExists, err)
+		}
+
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/create", map[string]interface{}{
+		"pool_name":  imageCreate.PoolName,
+		"namespace":  imageCreate.Namespace,
+		"image_name": imageCreate.Name,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// UpdateBlockImage updates the rbd image imageSpec (name, size, features, configuration)
+// and waits for the rbd/edit task (https://docs.ceph.com/en/latest/mgr/ceph_api/#put--api-block-image-image_spec).
+func (c *Client) UpdateBlockImage(imageSpec string, imageUpdate ImageUpdate) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if imageUpdate.Name == "" {
+		return 0, ceph.ErrImageNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(imageUpdate).
+		Put(c.getURL(fmt.Sprintf("block/image/%s", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not update image %s", imageSpec)); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return statusCode(resp), fmt.Errorf("%w: %v", ceph.ErrEditImageAlreadyExists, err)
+		}
+
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/edit", map[string]interface{}{
+		"image_spec": imageSpec,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// trashMove implements struct send to ceph on POST /api/block/image/{image_spec}/move_trash.
+type trashMove struct {
+	Delay int `json:"delay"`
+}
+
+// DeleteBlockImage deletes an RBD image and waits for the rbd/delete task
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#delete--api-block-image-image_spec).
+// Unlike ceph.Client.DeleteBlockImage a failed task is returned at once instead of retrying the delete.
+func (c *Client) DeleteBlockImage(imageSpec string) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		Delete(c.getURL(fmt.Sprintf("block/image/%s", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not delete image %s", imageSpec)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/delete", map[string]interface{}{
+		"image_spec": imageSpec,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// MoveBlockImageToTrash moves the rbd image imageSpec to the rbd trash, it can not be purged
+// before delay is expired (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-move_trash).
+func (c *Client) MoveBlockImageToTrash(imageSpec string, delay time.Duration) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(trashMove{Delay: int(delay.Seconds())}).
+		Post(c.getURL(fmt.Sprintf("block/image/%s/move_trash", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not move image %s to trash", imageSpec)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/trash/move", map[string]interface{}{
+		"image_spec": imageSpec,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// ImageCopy implements struct send to ceph on POST /api/block/image/{image_spec}/copy.
+// --> https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-copy
+type ImageCopy struct {
+	DestPoolName  string                 `json:"dest_pool_name"`
+	DestNamespace *string                `json:"dest_namespace"`
+	DestImageName string                 `json:"dest_image_name"`
+	SnapshotName  *string                `json:"snapshot_name"`
+	Features      []string               `json:"features"`
+	ObjSize       interface{}            `json:"obj_size"`
+	StripeUnit    interface{}            `json:"stripe_unit"`
+	StripeCount   interface{}            `json:"stripe_count"`
+	DataPool      interface{}            `json:"data_pool"`
+	Configuration map[string]interface{} `json:"configuration"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CopyBlockImage copies the rbd image imageSpec (or its snapshot ImageCopy.SnapshotName) to a new,
+// independent image and waits for the rbd/copy task.
+func (c *Client) CopyBlockImage(imageSpec string, imageCopy ImageCopy) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if imageCopy.DestPoolName == "" {
+		return 0, ceph.ErrPoolNameIsEmpty
+	}
+
+	if imageCopy.DestImageName == "" {
+		return 0, ceph.ErrImageNameIsEmpty
+	}
+
+	destSpec := ceph.PathJoin(imageCopy.DestPoolName, imageCopy.DestNamespace, imageCopy.DestImageName)
+
+	resp, err := c.newRequest().
+		SetBody(imageCopy).
+		Post(c.getURL(fmt.Sprintf("block/image/%s/copy", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not copy image %s to %s", imageSpec, destSpec)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/copy", map[string]interface{}{
+		"src_image_spec":  imageSpec,
+		"dest_pool_name":  imageCopy.DestPoolName,
+		"dest_namespace":  imageCopy.DestNamespace,
+		"dest_image_name": imageCopy.DestImageName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), fmt.Errorf("could not copy image %s to %s: %w", imageSpec, destSpec, err)
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// WaitForBlockImageCopy waits for an executing rbd/copy task to the destination image and returns an
+// error if it (or the last finished one) failed. Without such task nil is returned.
+func (c *Client) WaitForBlockImageCopy(destPoolName string, destNamespace *string, destImageName string) error {
+	metaData := map[string]interface{}{
+		"dest_pool_name":  destPoolName,
+		"dest_namespace":  destNamespace,
+		"dest_image_name": destImageName,
+	}
+
+	_, tasks, err := c.GetTasks("rbd/copy")
+
+	if err != nil {
+		return err
+	}
+
+	_, executing := findTask(tasks.ExecutingTasks, "rbd/copy", metaData)
+	_, finished := findTask(tasks.FinishedTasks, "rbd/copy", metaData)
+
+	if !executing && !finished {
+		return nil
+	}
+
+	return c.waitForTaskSuccess("rbd/copy", metaData)
+}
+
+// FlattenBlockImage copies all data of the parent snapshot into the cloned rbd image imageSpec and
+// removes its dependency on the parent (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-flatten).
+func (c *Client) FlattenBlockImage(imageSpec string) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		Post(c.getURL(fmt.Sprintf("block/image/%s/flatten", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not flatten image %s", imageSpec)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/flatten", map[string]interface{}{
+		"image_spec": imageSpec,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/go-resty/resty/v2"
+)
+
+var defaultHeaderJson = map[string]string{
+	"Accept":       "application/vnd.ceph.api.v1.0+json",
+	"Content-type": "application/json",
+}
+
+// Client extends the ceph rest client with typed errors and endpoints needed by the provider.
+type Client struct {
+	*ceph.Client
+}
+
+// New wraps a logged in ceph rest client.
+func New(client *ceph.Client) *Client {
+	return &Client{Client: client}
+}
+
+// getURL returns the api url for subPath on the server the session is connected to.
+func (c *Client) getURL(subPath string) string {
+	server := c.Session.Server
+
+	return fmt.Sprintf("%s://%s:%d/%s/%s",
+		server.Protocol,
+		server.Address,
+		server.Port,
+		server.APIPath,
+		subPath)
+}
+
+// newRequest returns a new json request on the session client.
+func (c *Client) newRequest() *resty.Request {
+	return c.Session.Client.R().SetHeaders(defaultHeaderJson)
+}
+
+// escapeSpec escapes an image or pool spec to be used as single path segment (/ becomes %2F).
+func escapeSpec(spec string) string {
+	return url.PathEscape(spec)
+}
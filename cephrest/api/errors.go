@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrNotFound is returned if the requested ceph object does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned if the ceph object conflicts with an existing one.
+var ErrConflict = errors.New("conflict")
+
+// ErrForbidden is returned if the ceph user lacks the permissions for a request.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUnauthorized is returned if the session is not (or no longer) authenticated.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// errno values returned by ceph in the code field of an exception.
+const (
+	errnoPermission = "1"
+	errnoNotFound   = "2"
+	errnoAccess     = "13"
+	errnoExists     = "17"
+)
+
+// checkResponse returns nil for successful responses and an error wrapping one of the
+// sentinel errors (if applicable) otherwise. what describes the failed operation.
+func checkResponse(resp *resty.Response, err error, what string) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+
+	if resp == nil || resp.IsSuccess() {
+		return nil
+	}
+
+	var (
+		exception ceph.Exception
+		detail    = resp.String()
+	)
+
+	if json.Unmarshal(resp.Body(), &exception) == nil && exception.Detail != "" {
+		detail = exception.Detail
+	}
+
+	if sentinel := statusError(resp.StatusCode(), exception.Code); sentinel != nil {
+		return fmt.Errorf("%s: %w (http status %d): %s", what, sentinel, resp.StatusCode(), detail)
+	}
+
+	return fmt.Errorf("%s: http status %d: %s", what, resp.StatusCode(), detail)
+}
+
+// statusError maps http status and ceph error code to a sentinel error.
+func statusError(status int, code string) error {
+	switch status {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	}
+
+	switch code {
+	case errnoNotFound:
+		return ErrNotFound
+	case errnoExists:
+		return ErrConflict
+	case errnoPermission, errnoAccess:
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// statusCode returns the http status of resp or 0 if there is no response.
+func statusCode(resp *resty.Response) int {
+	if resp == nil || resp.RawResponse == nil {
+		return 0
+	}
+
+	return resp.StatusCode()
+}
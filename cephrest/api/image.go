@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+)
+
+// Image implements struct returned from GET /api/block/image/{image_spec}, extending ceph.RBD by
+// the image metadata (rbd image-meta).
+type Image struct {
+	ceph.RBD
+	Metadata map[string]string `json:"metadata"`
+}
+
+// ImageRef implements the reference to an rbd image used for snapshot children.
+type ImageRef struct {
+	PoolName      string `json:"pool_name"`
+	PoolNamespace string `json:"pool_namespace"`
+	ImageName     string `json:"image_name"`
+}
+
+// Spec returns the image spec pool[/namespace]/image of the referenced image.
+func (r ImageRef) Spec() string {
+	return ceph.PathJoin(r.PoolName, r.PoolNamespace, r.ImageName)
+}
+
+// Snapshot implements a snapshot as listed in RBD.Snapshots.
+type Snapshot struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Size        int64      `json:"size"`
+	Timestamp   string     `json:"timestamp"`
+	IsProtected bool       `json:"is_protected"`
+	DiskUsage   int64      `json:"disk_usage"`
+	Children    []ImageRef `json:"children"`
+}
+
+// ImageSnapshots returns the snapshots of rbd typed (ceph.RBD keeps them as []interface{}).
+func ImageSnapshots(rbd ceph.RBD) (snapshots []Snapshot, err error) {
+	if len(rbd.Snapshots) == 0 {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(rbd.Snapshots)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(raw, &snapshots)
+
+	return snapshots, err
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+)
+
+// MirrorPool implements the mirroring configuration of a pool on GET / PUT /api/block/mirroring/pool/{pool_name}.
+type MirrorPool struct {
+	MirrorMode string `json:"mirror_mode"`
+}
+
+// SiteName implements the local rbd mirroring site name on GET / PUT /api/block/mirroring/site_name.
+type SiteName struct {
+	SiteName string `json:"site_name"`
+}
+
+// GetBlockMirroringPool gets the mirror mode of pool poolName
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-mirroring-pool-pool_name).
+func (c *Client) GetBlockMirroringPool(poolName string) (status int, mirrorPool MirrorPool, err error) {
+	resp, err := c.newRequest().
+		SetResult(&mirrorPool).
+		Get(c.getURL(fmt.Sprintf("block/mirroring/pool/%s", escapeSpec(poolName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not get mirroring of pool %s", poolName)); err != nil {
+		return statusCode(resp), mirrorPool, err
+	}
+
+	return resp.StatusCode(), mirrorPool, nil
+}
+
+// UpdateBlockMirroringPool sets the mirror mode (disabled, pool or image) of pool poolName and waits for the
+// rbd/mirroring/pool/edit task (https://docs.ceph.com/en/latest/mgr/ceph_api/#put--api-block-mirroring-pool-pool_name).
+func (c *Client) UpdateBlockMirroringPool(poolName string, mirrorPool MirrorPool) (status int, err error) {
+	resp, err := c.newRequest().
+		SetBody(mirrorPool).
+		Put(c.getURL(fmt.Sprintf("block/mirroring/pool/%s", escapeSpec(poolName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not update mirroring of pool %s", poolName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/mirroring/pool/edit", map[string]interface{}{
+		"pool_name": poolName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// GetBlockMirroringSiteName gets the local rbd mirroring site name
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-mirroring-site_name).
+func (c *Client) GetBlockMirroringSiteName() (status int, siteName SiteName, err error) {
+	resp, err := c.newRequest().
+		SetResult(&siteName).
+		Get(c.getURL("block/mirroring/site_name"))
+
+	if err = checkResponse(resp, err, "could not get mirroring site name"); err != nil {
+		return statusCode(resp), siteName, err
+	}
+
+	return resp.StatusCode(), siteName, nil
+}
+
+// UpdateBlockMirroringSiteName sets the local rbd mirroring site name
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#put--api-block-mirroring-site_name).
+func (c *Client) UpdateBlockMirroringSiteName(siteName SiteName) (status int, err error) {
+	resp, err := c.newRequest().
+		SetBody(siteName).
+		Put(c.getURL("block/mirroring/site_name"))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not set mirroring site name %s", siteName.SiteName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	return resp.StatusCode(), nil
+}
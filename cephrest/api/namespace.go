@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Namespace implements a namespace returned from GET /api/block/pool/{pool_name}/namespace.
+type Namespace struct {
+	Namespace string `json:"namespace"`
+	NumImages int    `json:"num_images"`
+}
+
+// namespaceCreate implements struct send to ceph on POST /api/block/pool/{pool_name}/namespace.
+type namespaceCreate struct {
+	Namespace string `json:"namespace"`
+}
+
+// ListBlockPoolNamespace lists the rbd namespaces of pool poolName
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-pool-pool_name-namespace).
+func (c *Client) ListBlockPoolNamespace(poolName string) (status int, namespaces []Namespace, err error) {
+	resp, err := c.newRequest().
+		SetResult(&namespaces).
+		Get(c.getURL(fmt.Sprintf("block/pool/%s/namespace", escapeSpec(poolName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not list namespaces of pool %s", poolName)); err != nil {
+		return statusCode(resp), nil, err
+	}
+
+	return resp.StatusCode(), namespaces, nil
+}
+
+// GetBlockPoolNamespace gets the rbd namespace nameSpace of pool poolName.
+func (c *Client) GetBlockPoolNamespace(poolName, nameSpace string) (status int, namespace Namespace, err error) {
+	status, namespaces, err := c.ListBlockPoolNamespace(poolName)
+
+	if err != nil {
+		return status, namespace, err
+	}
+
+	for _, namespace = range namespaces {
+		if namespace.Namespace == nameSpace {
+			return status, namespace, nil
+		}
+	}
+
+	return http.StatusNotFound, Namespace{}, fmt.Errorf("could not get namespace %s/%s: %w", poolName, nameSpace, ErrNotFound)
+}
+
+// CreateBlockPoolNamespace creates the rbd namespace nameSpace in pool poolName
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-pool-pool_name-namespace).
+func (c *Client) CreateBlockPoolNamespace(poolName, nameSpace string) (status int, err error) {
+	resp, err := c.newRequest().
+		SetBody(namespaceCreate{Namespace: nameSpace}).
+		Post(c.getURL(fmt.Sprintf("block/pool/%s/namespace", escapeSpec(poolName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not create namespace %s/%s", poolName, nameSpace)); err != nil {
+		return statusCode(resp), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// DeleteBlockPoolNamespace deletes the rbd namespace nameSpace of pool poolName
+// (https://docs.ceph.com/en/latest/mgr/ceph_api/#delete--api-block-pool-pool_name-namespace-namespace).
+func (c *Client) DeleteBlockPoolNamespace(poolName, nameSpace string) (status int, err error) {
+	resp, err := c.newRequest().
+		Delete(c.getURL(fmt.Sprintf("block/pool/%s/namespace/%s", escapeSpec(poolName), escapeSpec(nameSpace))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not delete namespace %s/%s", poolName, nameSpace)); err != nil {
+		return statusCode(resp), err
+	}
+
+	return resp.StatusCode(), nil
+}
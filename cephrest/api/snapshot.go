@@ -0,0 +1,224 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+)
+
+// ErrSnapshotNameIsEmpty is returned if param snapshotName is empty.
+var ErrSnapshotNameIsEmpty = errors.New("param snapshotName can not be empty")
+
+// ImageClone implements struct send to ceph on POST /api/block/image/{image_spec}/snap/{snapshot_name}/clone.
+// --> https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-snap-snapshot_name-clone
+type ImageClone struct {
+	ChildPoolName  string                 `json:"child_pool_name"`
+	ChildNamespace *string                `json:"child_namespace"`
+	ChildImageName string                 `json:"child_image_name"`
+	Features       []string               `json:"features"`
+	ObjSize        interface{}            `json:"obj_size"`
+	StripeUnit     interface{}            `json:"stripe_unit"`
+	StripeCount    interface{}            `json:"stripe_count"`
+	DataPool       interface{}            `json:"data_pool"`
+	Configuration  map[string]interface{} `json:"configuration"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CloneBlockImageSnapshot clones the (protected) snapshot snapshotName of the rbd image imageSpec
+// and waits for the rbd/clone task.
+func (c *Client) CloneBlockImageSnapshot(imageSpec, snapshotName string, imageClone ImageClone) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if imageClone.ChildPoolName == "" {
+		return 0, ceph.ErrPoolNameIsEmpty
+	}
+
+	if imageClone.ChildImageName == "" {
+		return 0, ceph.ErrImageNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(imageClone).
+		Post(c.getURL(fmt.Sprintf("block/image/%s/snap/%s/clone", escapeSpec(imageSpec), escapeSpec(snapshotName))))
+
+	what := fmt.Sprintf("could not clone %s@%s to %s", imageSpec, snapshotName,
+		ceph.PathJoin(imageClone.ChildPoolName, imageClone.ChildNamespace, imageClone.ChildImageName))
+
+	if err = checkResponse(resp, err, what); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/clone", map[string]interface{}{
+		"parent_image_spec": imageSpec,
+		"child_pool_name":   imageClone.ChildPoolName,
+		"child_namespace":   imageClone.ChildNamespace,
+		"child_image_name":  imageClone.ChildImageName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// snapshotCreate implements struct send to ceph on POST /api/block/image/{image_spec}/snap.
+type snapshotCreate struct {
+	SnapshotName string `json:"snapshot_name"`
+}
+
+// SnapshotUpdate implements struct send to ceph on PUT /api/block/image/{image_spec}/snap/{snapshot_name},
+// nil values are left untouched.
+type SnapshotUpdate struct {
+	NewSnapName *string `json:"new_snap_name"`
+	IsProtected *bool   `json:"is_protected"`
+}
+
+// GetBlockImageSnapshot gets the snapshot snapshotName of the rbd image imageSpec.
+func (c *Client) GetBlockImageSnapshot(imageSpec, snapshotName string) (status int, snapshot Snapshot, err error) {
+	status, rbd, err := c.GetBlockImage(imageSpec)
+
+	if err != nil {
+		return status, snapshot, err
+	}
+
+	snapshots, err := ImageSnapshots(rbd.RBD)
+
+	if err != nil {
+		return status, snapshot, err
+	}
+
+	for _, snapshot = range snapshots {
+		if snapshot.Name == snapshotName {
+			return status, snapshot, nil
+		}
+	}
+
+	return http.StatusNotFound, Snapshot{}, fmt.Errorf("could not get snapshot %s@%s: %w", imageSpec, snapshotName, ErrNotFound)
+}
+
+// CreateBlockImageSnapshot creates the snapshot snapshotName of the rbd image imageSpec and waits for
+// the rbd/snap/create task (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-snap).
+func (c *Client) CreateBlockImageSnapshot(imageSpec, snapshotName string) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if snapshotName == "" {
+		return 0, ErrSnapshotNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(snapshotCreate{SnapshotName: snapshotName}).
+		Post(c.getURL(fmt.Sprintf("block/image/%s/snap", escapeSpec(imageSpec))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not create snapshot %s@%s", imageSpec, snapshotName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/snap/create", map[string]interface{}{
+		"image_spec":    imageSpec,
+		"snapshot_name": snapshotName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// UpdateBlockImageSnapshot renames and / or (un)protects the snapshot snapshotName of the rbd image imageSpec
+// and waits for the rbd/snap/edit task (https://docs.ceph.com/en/latest/mgr/ceph_api/#put--api-block-image-image_spec-snap-snapshot_name).
+func (c *Client) UpdateBlockImageSnapshot(imageSpec, snapshotName string, snapshotUpdate SnapshotUpdate) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if snapshotName == "" {
+		return 0, ErrSnapshotNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		SetBody(snapshotUpdate).
+		Put(c.getURL(fmt.Sprintf("block/image/%s/snap/%s", escapeSpec(imageSpec), escapeSpec(snapshotName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not update snapshot %s@%s", imageSpec, snapshotName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/snap/edit", map[string]interface{}{
+		"image_spec":    imageSpec,
+		"snapshot_name": snapshotName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// DeleteBlockImageSnapshot deletes the (unprotected) snapshot snapshotName of the rbd image imageSpec and waits
+// for the rbd/snap/delete task (https://docs.ceph.com/en/latest/mgr/ceph_api/#delete--api-block-image-image_spec-snap-snapshot_name).
+func (c *Client) DeleteBlockImageSnapshot(imageSpec, snapshotName string) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if snapshotName == "" {
+		return 0, ErrSnapshotNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		Delete(c.getURL(fmt.Sprintf("block/image/%s/snap/%s", escapeSpec(imageSpec), escapeSpec(snapshotName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not delete snapshot %s@%s", imageSpec, snapshotName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/snap/delete", map[string]interface{}{
+		"image_spec":    imageSpec,
+		"snapshot_name": snapshotName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
+
+// RollbackBlockImageSnapshot rolls the rbd image imageSpec back to its snapshot snapshotName and waits for
+// the rbd/snap/rollback task (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-image_spec-snap-snapshot_name-rollback).
+func (c *Client) RollbackBlockImageSnapshot(imageSpec, snapshotName string) (status int, err error) {
+	if imageSpec == "" {
+		return 0, ceph.ErrImageSpecIsEmpty
+	}
+
+	if snapshotName == "" {
+		return 0, ErrSnapshotNameIsEmpty
+	}
+
+	resp, err := c.newRequest().
+		Post(c.getURL(fmt.Sprintf("block/image/%s/snap/%s/rollback", escapeSpec(imageSpec), escapeSpec(snapshotName))))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not rollback image %s to snapshot %s", imageSpec, snapshotName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/snap/rollback", map[string]interface{}{
+		"image_spec":    imageSpec,
+		"snapshot_name": snapshotName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+)
+
+// taskMaxAttempts is the number of polls for a task to show up in the finished tasks.
+const taskMaxAttempts = 600
+
+// taskPollInterval is the time to wait between two polls of /api/task.
+const taskPollInterval = 5 * time.Second
+
+// Task implements a task returned by /api/task. Unlike ceph.Task the metadata is kept
+// generic as it differs per task type (e.g. rbd/clone, rbd/snap/create).
+type Task struct {
+	Name      string                 `json:"name"`
+	MetaData  map[string]interface{} `json:"metadata"`
+	BeginTime time.Time              `json:"begin_time"`
+	EndTime   time.Time              `json:"end_time"`
+	Duration  float64                `json:"duration"`
+	Progress  int                    `json:"progress"`
+	Success   bool                   `json:"success"`
+	RetValue  interface{}            `json:"ret_value"`
+	Exception ceph.Exception         `json:"exception"`
+}
+
+// Tasks implements the struct returned by /api/task.
+type Tasks struct {
+	ExecutingTasks []Task `json:"executing_tasks"`
+	FinishedTasks  []Task `json:"finished_tasks"`
+}
+
+// GetTasks gets the executing and finished tasks named name (https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-task).
+func (c *Client) GetTasks(name string) (status int, tasks Tasks, err error) {
+	resp, err := c.newRequest().
+		SetQueryParam("name", name).
+		SetResult(&tasks).
+		Get(c.getURL("task"))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not get tasks %s", name)); err != nil {
+		return statusCode(resp), tasks, err
+	}
+
+	return resp.StatusCode(), tasks, nil
+}
+
+// WaitForTask waits until the task named name with matching metaData is finished and returns it.
+// It polls like ceph.Client.WaitForTaskIsDone: first until the task is no longer executing,
+// then until it shows up in the finished tasks.
+func (c *Client) WaitForTask(name string, metaData map[string]interface{}) (Task, error) {
+	for {
+		_, tasks, err := c.GetTasks(name)
+		if err != nil {
+			return Task{}, err
+		}
+
+		if _, executing := findTask(tasks.ExecutingTasks, name, metaData); !executing {
+			break
+		}
+
+		c.Logger.Debugf("still executing: %s %v", name, metaData)
+		time.Sleep(taskPollInterval)
+	}
+
+	for attempt := 0; attempt < taskMaxAttempts; attempt++ {
+		_, tasks, err := c.GetTasks(name)
+		if err != nil {
+			return Task{}, err
+		}
+
+		if task, finished := findTask(tasks.FinishedTasks, name, metaData); finished {
+			c.Logger.Debugf("finished: %s %v", name, metaData)
+			return task, nil
+		}
+
+		c.Logger.Debugf("still not done: %s %v %d", name, metaData, attempt)
+		time.Sleep(taskPollInterval)
+	}
+
+	return Task{}, fmt.Errorf("task %s %v did not finish after %d attempts", name, metaData, taskMaxAttempts)
+}
+
+// waitForTaskSuccess waits for the task and returns an error if the task failed.
+func (c *Client) waitForTaskSuccess(name string, metaData map[string]interface{}) error {
+	task, err := c.WaitForTask(name, metaData)
+
+	if err != nil {
+		return err
+	}
+
+	if !task.Success {
+		exception := task.Exception
+
+		if sentinel := statusError(exception.Status, exception.Code); sentinel != nil {
+			return fmt.Errorf("task %s %v failed: %w: %s", name, metaData, sentinel, exception.Detail)
+		}
+
+		return fmt.Errorf("task %s %v failed: %s", name, metaData, exception.Detail)
+	}
+
+	return nil
+}
+
+// findTask returns the last task in tasks named name having all key / values of metaData.
+func findTask(tasks []Task, name string, metaData map[string]interface{}) (Task, bool) {
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].Name == name && matchMetaData(tasks[i].MetaData, metaData) {
+			return tasks[i], true
+		}
+	}
+
+	return Task{}, false
+}
+
+func matchMetaData(taskMetaData, metaData map[string]interface{}) bool {
+	for key, value := range metaData {
+		if metaDataString(taskMetaData[key]) != metaDataString(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// metaDataString normalizes metadata values (nil and empty string are equal).
+func metaDataString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+
+	return fmt.Sprint(v)
+}
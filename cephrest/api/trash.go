@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+)
+
+// TrashImage implements an image in the rbd trash as listed by GET /api/block/image/trash.
+type TrashImage struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	PoolName         string `json:"pool_name"`
+	Namespace        string `json:"namespace"`
+	Source           string `json:"source"`
+	DeletionTime     string `json:"deletion_time"`
+	DefermentEndTime string `json:"deferment_end_time"`
+}
+
+// TrashList implements struct received from GET /api/block/image/trash.
+// --> https://docs.ceph.com/en/latest/mgr/ceph_api/#get--api-block-image-trash
+type TrashList []struct {
+	Status   int          `json:"status"`
+	Value    []TrashImage `json:"value"`
+	PoolName string       `json:"pool_name"`
+}
+
+// ListBlockImageTrash lists the images in the rbd trash of pool poolName (all pools if empty).
+func (c *Client) ListBlockImageTrash(poolName string) (status int, trashList TrashList, err error) {
+	req := c.newRequest().SetResult(&trashList)
+
+	if poolName != "" {
+		req.SetQueryParam("pool_name", poolName)
+	}
+
+	resp, err := req.Get(c.getURL("block/image/trash"))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not list trash of pool '%s'", poolName)); err != nil {
+		return statusCode(resp), nil, err
+	}
+
+	return resp.StatusCode(), trashList, nil
+}
+
+// PurgeBlockImageTrash removes all expired images from the rbd trash of pool poolName (all pools if empty)
+// and waits for the rbd/trash/purge task (https://docs.ceph.com/en/latest/mgr/ceph_api/#post--api-block-image-trash-purge).
+func (c *Client) PurgeBlockImageTrash(poolName string) (status int, err error) {
+	req := c.newRequest()
+
+	if poolName != "" {
+		req.SetQueryParam("pool_name", poolName)
+	}
+
+	resp, err := req.Post(c.getURL("block/image/trash/purge"))
+
+	if err = checkResponse(resp, err, fmt.Sprintf("could not purge trash of pool '%s'", poolName)); err != nil {
+		return statusCode(resp), err
+	}
+
+	err = c.waitForTaskSuccess("rbd/trash/purge", map[string]interface{}{
+		"pool_name": poolName,
+	})
+
+	if err != nil {
+		return resp.StatusCode(), err
+	}
+
+	return resp.StatusCode(), nil
+}
@@ -1,7 +1,12 @@
 package configuration
 
-import "github.com/chrisamti/ceph-rest-client/ceph"
+import "github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
 
 type Ceph struct {
-	Client *ceph.Client
+	Client *api.Client
+	// Workspace and OwnerTag make up the ownership marker written to created rbd images.
+	Workspace string
+	OwnerTag  string
+	// IgnoreOwnership allows to delete and adopt images not owned by this workspace.
+	IgnoreOwnership bool
 }
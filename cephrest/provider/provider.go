@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
 	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
 	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/service"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -62,8 +63,39 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("CEPH_INSECURE_SKIP_VERIFY", true),
 				Description: "skip verify unknown certs.",
 			},
+			"owner_workspace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_WORKSPACE", "default"),
+				Description: "Workspace written to the ownership marker of created rbd images. Terraform does not pass " +
+					"the selected workspace to providers, set it to terraform.workspace.",
+			},
+			"owner_tag": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CEPH_OWNER_TAG", ""),
+				Description: "Owner tag written to the ownership marker of created rbd images.",
+			},
+			"ignore_ownership": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CEPH_IGNORE_OWNERSHIP", false),
+				Description: "Delete and adopt rbd images regardless of their ownership marker.",
+			},
+		},
+		ResourcesMap: map[string]*schema.Resource{
+			"ceph_rbd":                   service.ResourceRBD(),
+			"ceph_rbd_snapshot":          service.ResourceRBDSnapshot(),
+			"ceph_rbd_snapshot_rollback": service.ResourceRBDSnapshotRollback(),
+			"ceph_rbd_namespace":         service.ResourceRBDNamespace(),
+			"ceph_rbd_trash_purge":       service.ResourceRBDTrashPurge(),
+			"ceph_rbd_mirror_pool":       service.ResourceRBDMirrorPool(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"ceph_rbd":        service.DataSourceRBD(),
+			"ceph_rbd_images": service.DataSourceRBDImages(),
+			"ceph_rbd_trash":  service.DataSourceRBDTrash(),
 		},
-		ResourcesMap:         map[string]*schema.Resource{"ceph_rbd": service.ResourceRBD()},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
@@ -115,7 +147,12 @@ func providerConfigure(_ context.Context, rd *schema.ResourceData) (interface{},
 		switch statusLogin {
 		case http.StatusCreated:
 			// discard all previous errors and return configuration
-			return &configuration.Ceph{Client: client}, diag.Diagnostics{}
+			return &configuration.Ceph{
+				Client:          api.New(client),
+				Workspace:       rd.Get("owner_workspace").(string),
+				OwnerTag:        rd.Get("owner_tag").(string),
+				IgnoreOwnership: rd.Get("ignore_ownership").(bool),
+			}, diag.Diagnostics{}
 		default:
 			// append error to diags
 			diags = append(diags, diag.Diagnostic{
@@ -2,48 +2,202 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
 	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"log"
+	"strings"
 )
 
 func ResourceRBD() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		CreateContext: resourceRBDCreate,
 		ReadContext:   resourceRBDRead,
 		DeleteContext: resourceRBDelete,
 		UpdateContext: resourceRBDUpdate,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceRBDImport,
 		},
 
 		Schema: map[string]*schema.Schema{
 			"pool_name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ceph pool (image is replaced on change unless migrate_on_pool_change is set)",
 			},
 			"img_name": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ceph rbd image name (renamed in place on change)",
 			},
 			"name_space": {
 				Type:        schema.TypeString,
 				Required:    false,
 				Optional:    true,
-				Description: "ceph name space",
+				Description: "ceph name space (image is replaced on change unless migrate_on_pool_change is set)",
+			},
+			"migrate_on_pool_change": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "copy the image to a changed pool / name space and remove the source instead of replacing it (images with snapshots are refused)",
 			},
 			"size": {
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "ceph rbd image size in bytes",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"size", "size_human", "source_snapshot", "source_image"},
+				Description:  "ceph rbd image size in bytes (size of the source for clones and copies if not set)",
+			},
+			"size_human": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"size"},
+				ValidateFunc:     validateSize,
+				DiffSuppressFunc: suppressSizeHumanDiff,
+				Description:      "ceph rbd image size with unit (e.g. 10GiB, 500G, 2TiB), alternative to size",
+			},
+			"allow_shrink": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "allow to decrease size (truncates the image and destroys data beyond the new size)",
+			},
+			"features": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(rbdFeaturesRequestable, false)},
+				Set:         schema.HashString,
+				Description: "ceph rbd image features (cluster defaults if not set), features set by ceph itself are ignored",
+			},
+			"object_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(4096),
+				Description:  "ceph rbd object size in bytes (cluster default if not set)",
+			},
+			"stripe_unit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "ceph rbd stripe unit in bytes (cluster default if not set)",
+			},
+			"stripe_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "ceph rbd stripe count (cluster default if not set)",
+			},
+			"data_pool": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "pool to store the image data in (e.g. erasure coded pool), metadata is kept in pool_name",
+			},
+			"qos": rbdQoSSchema(false),
+			"deletion_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      deletionPolicyDelete,
+				ValidateFunc: validation.StringInSlice([]string{deletionPolicyDelete, deletionPolicyTrash}, false),
+				Description:  "delete: delete the image on destroy, trash: move it to the rbd trash (restorable with rbd trash restore)",
+			},
+			"trash_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				ValidateFunc: validateDuration,
+				Description:  "duration (e.g. 72h) a trashed image is protected from purge (deletion_policy trash only)",
+			},
+			"force_delete_snapshots": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "unprotect and delete the snapshots of the image before deleting it (deletion_policy delete only)",
+			},
+			"owner_tag": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "owner tag of the ownership marker (overrides the provider owner_tag)",
+			},
+			"ignore_ownership": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "delete and adopt the image regardless of its ownership marker",
+			},
+			"owner": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ownership marker (workspace/owner tag) of the image",
+			},
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "take an already existing image matching size, features and pool over into state instead of failing on create",
+			},
+			"metadata": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateRBDMetadata,
+				Description:  "image metadata (rbd image-meta), keys not set here are left untouched",
+			},
+			"source_snapshot": {
+				Type:             schema.TypeList,
+				Optional:         true,
+				ForceNew:         true,
+				MaxItems:         1,
+				Elem:             &schema.Resource{Schema: rbdImageRefSchema(false)},
+				DiffSuppressFunc: suppressSourceSnapshotDiff,
+				Description:      "protected snapshot the image is created as clone of (ignored for existing images if not set or equal to parent)",
+			},
+			"source_image": rbdSourceImageSchema(),
+			"parent": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: rbdImageRefSchema(true)},
+				Description: "parent snapshot of a cloned image",
+			},
+			"flatten": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "flatten a cloned image (removes the dependency on its parent snapshot)",
 			},
 		},
+		CustomizeDiff: customdiff.All(
+			resourceRBDPoolCustomizeDiff,
+			resourceRBDFeaturesCustomizeDiff,
+			resourceRBDSizeHumanCustomizeDiff,
+			resourceRBDSizeCustomizeDiff,
+			resourceRBDFlattenCustomizeDiff,
+			resourceRBDOwnerCustomizeDiff,
+		),
 		// TODO: define TimeOuts
 
 	}
+
+	for key, attribute := range rbdImageComputedSchema() {
+		resource.Schema[key] = attribute
+	}
+
+	return resource
 }
 
 func resourceRBDRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -61,6 +215,13 @@ func resourceRBDRead(_ context.Context, d *schema.ResourceData, meta interface{}
 
 	_, rbd, err := client.GetBlockImage(imageSpec)
 
+	if errors.Is(err, api.ErrNotFound) {
+		// image was deleted outside of terraform -> plan re-create
+		log.Printf("[WARN] rbd image %s not found, removing from state", imageSpec)
+		d.SetId("")
+		return diags
+	}
+
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -90,6 +251,70 @@ func resourceRBDRead(_ context.Context, d *schema.ResourceData, meta interface{}
 		return diag.FromErr(err)
 	}
 
+	if err = d.Set("features", explicitFeatures(rbd.FeaturesName)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("object_size", rbd.ObjSize); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("stripe_unit", rbd.StripeUnit); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("stripe_count", rbd.StripeCount); err != nil {
+		return diag.FromErr(err)
+	}
+
+	dataPool, _ := rbd.DataPool.(string)
+
+	if err = d.Set("data_pool", dataPool); err != nil {
+		return diag.FromErr(err)
+	}
+
+	qos := flattenRBDQoS(rbd)
+
+	// a configured block with only unlimited (0) limits has no image options, keep it
+	if len(qos) == 0 && len(d.Get("qos").([]interface{})) > 0 {
+		unlimited := make(map[string]interface{}, len(rbdQoSOptions))
+
+		for attribute := range rbdQoSOptions {
+			unlimited[attribute] = 0
+		}
+
+		qos = []interface{}{unlimited}
+	}
+
+	if err = d.Set("qos", qos); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("owner", rbd.Metadata[rbdOwnerMetadataKey]); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("metadata", flattenRBDMetadata(d, rbd)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = setRBDImageComputed(d, rbd); err != nil {
+		return diag.FromErr(err)
+	}
+
+	parent := flattenRBDParent(rbd.Parent)
+
+	if err = d.Set("parent", parent); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// a flattened image got a parent again (e.g. re-parented) -> show drift to flatten it again
+	if d.Get("flatten").(bool) && len(parent) > 0 {
+		if err = d.Set("flatten", false); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return diags
 
 }
@@ -120,26 +345,84 @@ func resourceRBDCreate(ctx context.Context, d *schema.ResourceData, meta interfa
 	poolName = d.Get("pool_name").(string)
 	imgName = d.Get("img_name").(string)
 
-	if d.Get("name_space").(string) != "" {
-		*nameSpace = d.Get("name_space").(string)
+	nameSpace = nameSpacePtr(d)
+
+	size, err := rbdSize(d)
+
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	rbd := ceph.RBDCreate{
-		Features:      nil,
+	rbd := api.ImageCreate{
+		Features:      expandStringSet(d.Get("features")),
 		PoolName:      poolName,
 		Namespace:     nameSpace,
 		Name:          imgName,
-		Size:          d.Get("size").(int),
-		ObjSize:       0,
+		Size:          size,
+		ObjSize:       d.Get("object_size").(int),
 		StripeUnit:    nil,
 		StripeCount:   nil,
 		DataPool:      nil,
-		Configuration: struct{}{},
+		Configuration: expandRBDQoS(d.Get("qos"), false),
+		Metadata:      rbdOwnerMetadata(cephConf, d),
+	}
+
+	// striping and data pool are left to ceph defaults if not set
+	if stripeUnit, ok := d.GetOk("stripe_unit"); ok {
+		rbd.StripeUnit = stripeUnit.(int)
+	}
+
+	if stripeCount, ok := d.GetOk("stripe_count"); ok {
+		rbd.StripeCount = stripeCount.(int)
 	}
 
-	log.Printf("[DEBUG] creating rbd image %s %v %s %d", poolName, nameSpace, imgName, d.Get("size").(int))
+	if dataPool, ok := d.GetOk("data_pool"); ok {
+		rbd.DataPool = dataPool.(string)
+	}
+
+	for key, value := range d.Get("metadata").(map[string]interface{}) {
+		rbd.Metadata[key] = value
+	}
+
+	log.Printf("[DEBUG] creating rbd image %s %v %s %d", poolName, nameSpace, imgName, size)
+
+	switch {
+	case len(d.Get("source_snapshot").([]interface{})) > 0:
+		err = cloneRBDImage(client, d, rbd)
+
+		if err == nil && d.Get("flatten").(bool) {
+			imageSpec := ceph.PathJoin(poolName, nameSpace, imgName)
 
-	_, err := client.CreateBlockImage(rbd, 0)
+			// keep the clone in state if flatten fails
+			_, clone, err := client.GetBlockImage(imageSpec)
+
+			if err != nil {
+				return diag.FromErr(err)
+			}
+
+			d.SetId(clone.UniqueID)
+
+			if err = flattenRBDImage(client, d, imageSpec); err != nil {
+				// a warning keeps the clone (an error would taint and replace it), flatten is planned in place
+				_ = d.Set("flatten", false)
+
+				return append(diag.Diagnostics{{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("rbd image %s could not be flattened", imageSpec),
+					Detail:   fmt.Sprintf("flatten is retried on next apply: %v", err),
+				}}, resourceRBDRead(ctx, d, meta)...)
+			}
+		}
+	case len(d.Get("source_image").([]interface{})) > 0:
+		err = copyRBDImage(client, d, rbd)
+	default:
+		_, err = client.CreateBlockImage(rbd)
+	}
+
+	// e.g. an interrupted apply created the image without saving it to state
+	if err != nil && imageAlreadyExists(err) && d.Get("adopt_existing").(bool) {
+		return adoptExistingRBDImage(ctx, d, meta, rbd)
+	}
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -156,15 +439,22 @@ func resourceRBDelete(_ context.Context, d *schema.ResourceData, meta interface{
 	// ConfigureContextFunc
 	cephConf := meta.(*configuration.Ceph)
 
-	client := cephConf.Client
+	nameSpace := nameSpacePtr(d)
+
+	err := removeRBDImage(cephConf, d, d.Get("pool_name").(string), nameSpace, d.Get("img_name").(string))
 
-	var nameSpace *string
+	var (
+		blocked  *deletionBlockedError
+		notOwned *ownershipError
+	)
 
-	if d.Get("name_space").(string) != "" {
-		*nameSpace = d.Get("name_space").(string)
+	if errors.As(err, &blocked) {
+		return blocked.diagnostics()
 	}
 
-	_, err := client.DeleteBlockImage(d.Get("pool_name").(string), nameSpace, d.Get("img_name").(string), 0)
+	if errors.As(err, &notOwned) {
+		return notOwned.diagnostics()
+	}
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -178,9 +468,10 @@ func resourceRBDelete(_ context.Context, d *schema.ResourceData, meta interface{
 
 func resourceRBDUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var (
-		nameSpace *string
-		poolName  string
-		imgName   string
+		nameSpace  *string
+		poolName   string
+		oldImgName string
+		newImgName string
 	)
 
 	// ConfigureContextFunc
@@ -188,25 +479,203 @@ func resourceRBDUpdate(ctx context.Context, d *schema.ResourceData, meta interfa
 
 	client := cephConf.Client
 
-	if d.Get("name_space").(string) != "" {
-		*nameSpace = d.Get("name_space").(string)
-	}
+	nameSpace = nameSpacePtr(d)
 
 	poolName = d.Get("pool_name").(string)
-	imgName = d.Get("img_name").(string)
 
-	rbdUpdate := ceph.RBDUpdate{
+	// the image is addressed by its current name, a new name renames it in place.
+	o, n := d.GetChange("img_name")
+	oldImgName, newImgName = o.(string), n.(string)
+
+	rbdUpdate := api.ImageUpdate{
 		Features:      nil,
-		Name:          imgName,
-		Size:          d.Get("img_size").(int64),
-		Configuration: struct{}{},
+		Name:          newImgName,
+		Size:          int64(d.Get("size").(int)),
+		Configuration: nil,
+	}
+
+	// features are only sent on change, ceph enables / disables the difference.
+	if d.HasChange("features") {
+		rbdUpdate.Features = append([]string{}, expandStringSet(d.Get("features"))...)
+	}
+
+	if d.HasChange("qos") {
+		rbdUpdate.Configuration = expandRBDQoS(d.Get("qos"), true)
+	}
+
+	if d.HasChanges("owner", "metadata") {
+		rbdUpdate.Metadata = expandRBDMetadataChange(d)
+
+		// planned by resourceRBDOwnerCustomizeDiff
+		if d.HasChange("owner") {
+			oldOwner, _ := d.GetChange("owner")
+			imageSpec := ceph.PathJoin(poolName, nameSpace, oldImgName)
+
+			var notOwned *ownershipError
+
+			if err := checkRBDOwnerTakeover(cephConf, d, imageSpec, oldOwner.(string)); errors.As(err, &notOwned) {
+				d.Partial(true)
+				return notOwned.diagnostics()
+			}
+
+			rbdUpdate.Metadata[rbdOwnerMetadataKey] = rbdOwner(cephConf, d)
+		}
 	}
 
-	_, err := client.UpdateBlockImage(poolName, nameSpace, imgName, rbdUpdate, 0)
+	// warnings of the migration
+	var diags diag.Diagnostics
+
+	// migrate_on_pool_change: the image is copied with its new name to the new pool / name space
+	migrated := false
+
+	if d.HasChanges("pool_name", "name_space") {
+		warnings, err := migrateRBDImage(cephConf, d)
+
+		if err != nil {
+			d.Partial(true)
+			return diag.FromErr(err)
+		}
+
+		diags = append(diags, warnings...)
+		oldImgName = newImgName
+		migrated = true
+	}
+
+	// e.g. deletion_policy changes are only kept in state
+	if oldImgName != newImgName || d.HasChanges("size", "features", "qos", "owner", "metadata") {
+		log.Printf("[DEBUG] updating rbd image %s (name %s, size %d)", ceph.PathJoin(poolName, nameSpace, oldImgName), newImgName, rbdUpdate.Size)
+
+		_, err := client.UpdateBlockImage(ceph.PathJoin(poolName, nameSpace, oldImgName), rbdUpdate)
+
+		if err != nil {
+			if migrated {
+				// state follows the migrated image, read it back so the failed changes stay planned
+				return append(append(diags, diag.FromErr(err)...), resourceRBDRead(ctx, d, meta)...)
+			}
+
+			// keep the old values (e.g. img_name) in state
+			d.Partial(true)
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("flatten") && d.Get("flatten").(bool) {
+		if err := flattenRBDImage(client, d, ceph.PathJoin(poolName, nameSpace, newImgName)); err != nil {
+			// flatten is retried on next apply
+			_ = d.Set("flatten", false)
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+
+	return append(diags, resourceRBDRead(ctx, d, meta)...)
+}
+
+// rbdImportDefaults are the values of attributes not read from ceph for imported images.
+var rbdImportDefaults = map[string]interface{}{
+	"allow_shrink":           false,
+	"deletion_policy":        deletionPolicyDelete,
+	"trash_delay":            "0s",
+	"flatten":                false,
+	"migrate_on_pool_change": false,
+	"force_delete_snapshots": false,
+	"ignore_ownership":       false,
+	"adopt_existing":         false,
+}
+
+// resourceRBDImport imports an existing rbd image by its image spec "pool[/namespace]/image".
+func resourceRBDImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	poolName, nameSpace, imgName, err := parseImageSpec(d.Id())
 
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
 	}
 
-	return resourceRBDRead(ctx, d, meta)
+	if err = d.Set("pool_name", poolName); err != nil {
+		return nil, err
+	}
+
+	if err = d.Set("name_space", nameSpace); err != nil {
+		return nil, err
+	}
+
+	if err = d.Set("img_name", imgName); err != nil {
+		return nil, err
+	}
+
+	// attributes not known by ceph are set to their defaults
+	for key, value := range rbdImportDefaults {
+		if err = d.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if diags := resourceRBDRead(ctx, d, meta); diags.HasError() {
+		return nil, diagsToError(diags)
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("rbd image %s: %w", ceph.PathJoin(poolName, nameSpace, imgName), api.ErrNotFound)
+	}
+
+	// import is read only: an image without ownership marker gets it planned by resourceRBDOwnerCustomizeDiff,
+	// an image marked by another workspace is refused
+	imageSpec := ceph.PathJoin(poolName, nameSpace, imgName)
+
+	if err = checkRBDOwnerTakeover(meta.(*configuration.Ceph), d, imageSpec, d.Get("owner").(string)); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// parseImageSpec splits an image spec "pool[/namespace]/image" into its parts.
+func parseImageSpec(imageSpec string) (poolName, nameSpace, imgName string, err error) {
+	parts := strings.Split(imageSpec, "/")
+
+	for _, part := range parts {
+		if part == "" {
+			return "", "", "", fmt.Errorf("invalid image spec '%s': expected pool[/namespace]/image", imageSpec)
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return parts[0], "", parts[1], nil
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	}
+
+	return "", "", "", fmt.Errorf("invalid image spec '%s': expected pool[/namespace]/image", imageSpec)
+}
+
+// nameSpacePtr returns name_space as pointer as expected by the ceph client (nil if not set).
+func nameSpacePtr(d *schema.ResourceData) *string {
+	nameSpace, ok := d.GetOk("name_space")
+
+	if !ok || nameSpace.(string) == "" {
+		return nil
+	}
+
+	ns := nameSpace.(string)
+
+	return &ns
+}
+
+// diagsToError converts the error diagnostics to a single error.
+func diagsToError(diags diag.Diagnostics) error {
+	var msgs []string
+
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+
+		if d.Detail != "" {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", d.Summary, d.Detail))
+		} else {
+			msgs = append(msgs, d.Summary)
+		}
+	}
+
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
 }
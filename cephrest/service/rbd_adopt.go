@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// imageAlreadyExists returns true if err reports an already existing image on create, clone or copy.
+func imageAlreadyExists(err error) bool {
+	return errors.Is(err, ceph.ErrCreateImageAlreadyExists) || errors.Is(err, api.ErrConflict)
+}
+
+// adoptExistingRBDImage takes an already existing image over into state (adopt_existing) if it matches
+// the requested image rbd.
+func adoptExistingRBDImage(ctx context.Context, d *schema.ResourceData, meta interface{}, rbd api.ImageCreate) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(rbd.PoolName, rbd.Namespace, rbd.Name)
+
+	// ceph creates the full size destination before copying, a copy has to be complete to be adopted
+	if len(d.Get("source_image").([]interface{})) > 0 {
+		if err := client.WaitForBlockImageCopy(rbd.PoolName, rbd.Namespace, rbd.Name); err != nil {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("rbd image %s already exists and is not adopted", imageSpec),
+				Detail:   fmt.Sprintf("the copy to the image did not complete: %v", err),
+			}}
+		}
+	}
+
+	_, image, err := client.GetBlockImage(imageSpec)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// a clone has to have the configured parent snapshot, unless it was flattened already
+	// (e.g. an apply interrupted while waiting for the flatten)
+	var parent []interface{}
+
+	source := d.Get("source_snapshot").([]interface{})
+	flattened := d.Get("flatten").(bool) && len(flattenRBDParent(image.Parent)) == 0
+
+	if len(source) > 0 && !flattened {
+		parent = source
+	}
+
+	if mismatches := compareRBDImage(image, rbd, parent); len(mismatches) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("rbd image %s already exists and does not match the configuration", imageSpec),
+			Detail:   "the existing image is not adopted:\n" + strings.Join(mismatches, "\n"),
+		}}
+	}
+
+	log.Printf("[DEBUG] adopting existing rbd image %s", imageSpec)
+
+	var notOwned *ownershipError
+
+	if err = adoptRBDImage(cephConf, d); errors.As(err, &notOwned) {
+		return notOwned.diagnostics()
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the adopted image is in state now, failures of the remaining configuration are returned as warnings
+	// so they are planned as in place update (an error would taint and replace the image)
+	var diags diag.Diagnostics
+
+	d.SetId(image.UniqueID)
+
+	// qos and metadata are not compared, they are applied to the adopted image
+	_, err = client.UpdateBlockImage(imageSpec, api.ImageUpdate{
+		Name:          image.Name,
+		Size:          image.Size,
+		Configuration: expandRBDQoS(d.Get("qos"), true),
+		Metadata:      d.Get("metadata").(map[string]interface{}),
+	})
+
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("qos and metadata of adopted rbd image %s could not be applied", imageSpec),
+			Detail:   fmt.Sprintf("they are applied on next apply: %v", err),
+		})
+	}
+
+	if d.Get("flatten").(bool) {
+		if err = flattenRBDImage(client, d, imageSpec); err != nil {
+			// flatten is retried on next apply
+			_ = d.Set("flatten", false)
+
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("adopted rbd image %s could not be flattened", imageSpec),
+				Detail:   fmt.Sprintf("flatten is retried on next apply: %v", err),
+			})
+		}
+	}
+
+	return append(diags, resourceRBDRead(ctx, d, meta)...)
+}
+
+// compareRBDImage returns the differences of the existing image to the requested one (and its parent
+// snapshot if requested as clone).
+func compareRBDImage(image api.Image, rbd api.ImageCreate, parent []interface{}) (mismatches []string) {
+	nameSpace, _ := image.Namespace.(string)
+
+	if image.PoolName != rbd.PoolName || nameSpace != ceph.PathJoin(rbd.Namespace) {
+		mismatches = append(mismatches, fmt.Sprintf("- pool: existing %s, requested %s",
+			ceph.PathJoin(image.PoolName, nameSpace), ceph.PathJoin(rbd.PoolName, rbd.Namespace)))
+	}
+
+	if rbd.Size > 0 && image.Size != rbd.Size {
+		mismatches = append(mismatches, fmt.Sprintf("- size: existing %d, requested %d", image.Size, rbd.Size))
+	}
+
+	if rbd.Features != nil {
+		existing := explicitFeatures(image.FeaturesName)
+		requested := explicitFeatures(rbd.Features)
+		sort.Strings(existing)
+		sort.Strings(requested)
+
+		if strings.Join(existing, ",") != strings.Join(requested, ",") {
+			mismatches = append(mismatches, fmt.Sprintf("- features: existing [%s], requested [%s]",
+				strings.Join(existing, ", "), strings.Join(requested, ", ")))
+		}
+	}
+
+	if dataPool, ok := rbd.DataPool.(string); ok {
+		if existing, _ := image.DataPool.(string); existing != dataPool {
+			mismatches = append(mismatches, fmt.Sprintf("- data_pool: existing '%s', requested '%s'", existing, dataPool))
+		}
+	}
+
+	if len(parent) > 0 {
+		if existing := flattenRBDParent(image.Parent); rbdImageRefString(existing) != rbdImageRefString(parent) {
+			mismatches = append(mismatches, fmt.Sprintf("- parent: existing '%s', requested '%s'",
+				rbdImageRefString(existing), rbdImageRefString(parent)))
+		}
+	}
+
+	return mismatches
+}
+
+// rbdImageRefString returns the snapshot spec pool[/namespace]/image@snapshot of a source_snapshot or
+// parent block ("" if not set).
+func rbdImageRefString(ref []interface{}) string {
+	if len(ref) == 0 || ref[0] == nil {
+		return ""
+	}
+
+	r := ref[0].(map[string]interface{})
+
+	return fmt.Sprintf("%s@%s", ceph.PathJoin(r["pool"].(string), r["namespace"].(string), r["image"].(string)), r["snapshot"])
+}
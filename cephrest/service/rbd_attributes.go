@@ -0,0 +1,165 @@
+package service
+
+import (
+	"time"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rbdImageComputedSchema returns the read only attributes of an rbd image.
+func rbdImageComputedSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"unique_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "unique id of the image (pool id / image id)",
+		},
+		"image_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "rbd image id",
+		},
+		"block_name_prefix": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "prefix of the rados objects holding the image data",
+		},
+		"image_format": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "rbd image format",
+		},
+		"features_name": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "features enabled on the image as reported by ceph",
+		},
+		"timestamp": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "creation time of the image (RFC 3339)",
+		},
+		"disk_usage": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "bytes used by the image head",
+		},
+		"total_disk_usage": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "bytes used by the image including its snapshots",
+		},
+		"num_objs": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "number of rados objects of the provisioned size",
+		},
+		"order": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "object size as power of two",
+		},
+		"snapshots": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"id": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"size": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"timestamp": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"is_protected": {
+						Type:     schema.TypeBool,
+						Computed: true,
+					},
+					"disk_usage": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"children": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "image specs of the clones of the snapshot",
+					},
+				},
+			},
+			Description: "snapshots of the image",
+		},
+	}
+}
+
+// setRBDImageComputed sets the read only attributes of rbdImageComputedSchema.
+func setRBDImageComputed(d *schema.ResourceData, rbd api.Image) error {
+	snapshots, err := api.ImageSnapshots(rbd.RBD)
+
+	if err != nil {
+		return err
+	}
+
+	var timestamp string
+
+	if !rbd.Timestamp.IsZero() {
+		timestamp = rbd.Timestamp.Format(time.RFC3339)
+	}
+
+	values := map[string]interface{}{
+		"unique_id":         rbd.UniqueID,
+		"image_id":          rbd.ID,
+		"block_name_prefix": rbd.BlockNamePrefix,
+		"image_format":      rbd.ImageFormat,
+		"features_name":     rbd.FeaturesName,
+		"timestamp":         timestamp,
+		"disk_usage":        rbd.DiskUsage,
+		"total_disk_usage":  rbd.TotalDiskUsage,
+		"num_objs":          rbd.NumObjs,
+		"order":             rbd.Order,
+		"snapshots":         flattenRBDSnapshots(snapshots),
+	}
+
+	for key, value := range values {
+		if err = d.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func flattenRBDSnapshots(snapshots []api.Snapshot) []interface{} {
+	list := make([]interface{}, 0, len(snapshots))
+
+	for _, snapshot := range snapshots {
+		children := make([]interface{}, 0, len(snapshot.Children))
+
+		for _, child := range snapshot.Children {
+			children = append(children, child.Spec())
+		}
+
+		list = append(list, map[string]interface{}{
+			"id":           snapshot.ID,
+			"name":         snapshot.Name,
+			"size":         snapshot.Size,
+			"timestamp":    snapshot.Timestamp,
+			"is_protected": snapshot.IsProtected,
+			"disk_usage":   snapshot.DiskUsage,
+			"children":     children,
+		})
+	}
+
+	return list
+}
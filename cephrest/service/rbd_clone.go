@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rbdImageRefSchema returns the attributes referencing an rbd image snapshot (source_snapshot, parent).
+func rbdImageRefSchema(computed bool) map[string]*schema.Schema {
+	ref := map[string]*schema.Schema{
+		"pool": {
+			Type:        schema.TypeString,
+			Required:    !computed,
+			Computed:    computed,
+			Description: "pool of the parent image",
+		},
+		"namespace": {
+			Type:        schema.TypeString,
+			Optional:    !computed,
+			Computed:    computed,
+			Description: "name space of the parent image",
+		},
+		"image": {
+			Type:        schema.TypeString,
+			Required:    !computed,
+			Computed:    computed,
+			Description: "name of the parent image",
+		},
+		"snapshot": {
+			Type:        schema.TypeString,
+			Required:    !computed,
+			Computed:    computed,
+			Description: "name of the parent snapshot",
+		},
+	}
+
+	if !computed {
+		for _, attribute := range ref {
+			attribute.DiffSuppressFunc = suppressSourceSnapshotDiff
+		}
+	}
+
+	return ref
+}
+
+// suppressSourceSnapshotDiff suppresses source_snapshot changes of an existing image if it is not set
+// (e.g. an imported clone) or set to the parent of the image, so the image is not replaced.
+func suppressSourceSnapshotDiff(_, _, _ string, d *schema.ResourceData) bool {
+	if d.Id() == "" {
+		return false
+	}
+
+	source := d.Get("source_snapshot").([]interface{})
+
+	if len(source) == 0 || source[0] == nil {
+		return true
+	}
+
+	parent := d.Get("parent").([]interface{})
+
+	if len(parent) == 0 || parent[0] == nil {
+		return false
+	}
+
+	sourceRef, parentRef := source[0].(map[string]interface{}), parent[0].(map[string]interface{})
+
+	for _, key := range []string{"pool", "namespace", "image", "snapshot"} {
+		if sourceRef[key] != parentRef[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flattenRBDParent converts RBD.Parent ({pool_name, pool_namespace, image_name, snap_name}) to the parent block.
+func flattenRBDParent(parent interface{}) []interface{} {
+	p, ok := parent.(map[string]interface{})
+
+	if !ok || len(p) == 0 {
+		return []interface{}{}
+	}
+
+	str := func(key string) string {
+		s, _ := p[key].(string)
+		return s
+	}
+
+	return []interface{}{map[string]interface{}{
+		"pool":      str("pool_name"),
+		"namespace": str("pool_namespace"),
+		"image":     str("image_name"),
+		"snapshot":  str("snap_name"),
+	}}
+}
+
+// cloneRBDImage creates the image as clone of source_snapshot with the options of rbd
+// and resizes it if a size other than the parent size is configured.
+func cloneRBDImage(client *api.Client, d *schema.ResourceData, rbd api.ImageCreate) error {
+	source := d.Get("source_snapshot").([]interface{})[0].(map[string]interface{})
+
+	parentSpec := ceph.PathJoin(source["pool"].(string), source["namespace"].(string), source["image"].(string))
+	snapshotName := source["snapshot"].(string)
+
+	imageClone := api.ImageClone{
+		ChildPoolName:  rbd.PoolName,
+		ChildNamespace: rbd.Namespace,
+		ChildImageName: rbd.Name,
+		Features:       rbd.Features,
+		StripeUnit:     rbd.StripeUnit,
+		StripeCount:    rbd.StripeCount,
+		DataPool:       rbd.DataPool,
+		Configuration:  rbd.Configuration,
+		Metadata:       rbd.Metadata,
+	}
+
+	if rbd.ObjSize > 0 {
+		imageClone.ObjSize = rbd.ObjSize
+	}
+
+	// a clone can not be smaller than its parent, check before the clone is created
+	_, snapshot, err := client.GetBlockImageSnapshot(parentSpec, snapshotName)
+
+	if err != nil {
+		return err
+	}
+
+	if err = checkRBDImageSourceSize(rbd, snapshot.Size, fmt.Sprintf("%s@%s", parentSpec, snapshotName)); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] cloning rbd image %s@%s to %s", parentSpec, snapshotName, ceph.PathJoin(rbd.PoolName, rbd.Namespace, rbd.Name))
+
+	if _, err = client.CloneBlockImageSnapshot(parentSpec, snapshotName, imageClone); err != nil {
+		return err
+	}
+
+	return resizeRBDImage(client, rbd, fmt.Sprintf("%s@%s", parentSpec, snapshotName))
+}
+
+// checkRBDImageSourceSize returns an error if the configured size (if set) of the clone or copy rbd is
+// smaller than the size of its source.
+func checkRBDImageSourceSize(rbd api.ImageCreate, sourceSize int64, source string) error {
+	if rbd.Size > 0 && rbd.Size < sourceSize {
+		return fmt.Errorf("configured size %d of image %s is smaller than size %d of its source %s",
+			rbd.Size, ceph.PathJoin(rbd.PoolName, rbd.Namespace, rbd.Name), sourceSize, source)
+	}
+
+	return nil
+}
+
+// resizeRBDImage grows the clone or copy of source to the configured size (if set).
+func resizeRBDImage(client *api.Client, rbd api.ImageCreate, source string) error {
+	if rbd.Size == 0 {
+		// image keeps the size of its source
+		return nil
+	}
+
+	imageSpec := ceph.PathJoin(rbd.PoolName, rbd.Namespace, rbd.Name)
+
+	_, image, err := client.GetBlockImage(imageSpec)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case rbd.Size == image.Size:
+		return nil
+	case rbd.Size < image.Size:
+		return fmt.Errorf("image %s created from %s has size %d, configured size %d is smaller than its source",
+			imageSpec, source, image.Size, rbd.Size)
+	}
+
+	_, err = client.UpdateBlockImage(imageSpec, api.ImageUpdate{Name: rbd.Name, Size: rbd.Size})
+
+	return err
+}
+
+// flattenRBDImage flattens the image if it (still) has a parent.
+func flattenRBDImage(client *api.Client, d *schema.ResourceData, imageSpec string) error {
+	if len(d.Get("parent").([]interface{})) == 0 {
+		_, image, err := client.GetBlockImage(imageSpec)
+
+		if err != nil {
+			return err
+		}
+
+		if len(flattenRBDParent(image.Parent)) == 0 {
+			return nil
+		}
+	}
+
+	log.Printf("[DEBUG] flattening rbd image %s", imageSpec)
+
+	_, err := client.FlattenBlockImage(imageSpec)
+
+	return err
+}
+
+// resourceRBDFlattenCustomizeDiff plans an empty parent if the image is going to be flattened.
+func resourceRBDFlattenCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("flatten") || !d.Get("flatten").(bool) {
+		return nil
+	}
+
+	return d.SetNew("parent", []interface{}{})
+}
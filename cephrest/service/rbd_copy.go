@@ -0,0 +1,140 @@
+package service
+
+import (
+	"log"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func rbdSourceImageSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		ForceNew: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"pool": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "pool of the source image",
+				},
+				"namespace": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "name space of the source image",
+				},
+				"image": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "name of the source image",
+				},
+				"snapshot": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "snapshot of the source image to copy (image head if not set)",
+				},
+			},
+		},
+		ConflictsWith: []string{"source_snapshot"},
+		Description:   "image the new image is created as full, independent copy of",
+	}
+}
+
+// copyRBDImage creates the image as copy of source_image.
+func copyRBDImage(client *api.Client, d *schema.ResourceData, rbd api.ImageCreate) error {
+	source := d.Get("source_image").([]interface{})[0].(map[string]interface{})
+
+	sourceSpec := ceph.PathJoin(source["pool"].(string), source["namespace"].(string), source["image"].(string))
+
+	return copyRBDImageFrom(client, sourceSpec, source["snapshot"].(string), rbd)
+}
+
+// copyRBDImageFrom copies the image sourceSpec (or its snapshot) to the image rbd. Features, striping and
+// image configuration not set in rbd are carried over from the source image.
+func copyRBDImageFrom(client *api.Client, sourceSpec, snapshot string, rbd api.ImageCreate) error {
+	_, sourceImage, err := client.GetBlockImage(sourceSpec)
+
+	if err != nil {
+		return err
+	}
+
+	imageCopy := api.ImageCopy{
+		DestPoolName:  rbd.PoolName,
+		DestNamespace: rbd.Namespace,
+		DestImageName: rbd.Name,
+		Features:      rbd.Features,
+		StripeUnit:    rbd.StripeUnit,
+		StripeCount:   rbd.StripeCount,
+		DataPool:      rbd.DataPool,
+		Configuration: make(map[string]interface{}),
+		Metadata:      rbd.Metadata,
+	}
+
+	// sourceName is the copied image or snapshot used in messages
+	sourceName := sourceSpec
+
+	// sourceSize is the size of the copied image or snapshot
+	sourceSize := sourceImage.Size
+
+	if snapshot != "" {
+		imageCopy.SnapshotName = &snapshot
+		sourceName += "@" + snapshot
+
+		_, sourceSnapshot, err := client.GetBlockImageSnapshot(sourceSpec, snapshot)
+
+		if err != nil {
+			return err
+		}
+
+		sourceSize = sourceSnapshot.Size
+	}
+
+	// a copy can not be smaller than its source, check before the copy is created
+	if err = checkRBDImageSourceSize(rbd, sourceSize, sourceName); err != nil {
+		return err
+	}
+
+	if imageCopy.Features == nil {
+		imageCopy.Features = explicitFeatures(sourceImage.FeaturesName)
+	}
+
+	if rbd.ObjSize > 0 {
+		imageCopy.ObjSize = rbd.ObjSize
+	} else if sourceImage.ObjSize > 0 {
+		imageCopy.ObjSize = sourceImage.ObjSize
+	}
+
+	if imageCopy.StripeUnit == nil && sourceImage.StripeUnit > 0 {
+		imageCopy.StripeUnit = sourceImage.StripeUnit
+	}
+
+	if imageCopy.StripeCount == nil && sourceImage.StripeCount > 0 {
+		imageCopy.StripeCount = sourceImage.StripeCount
+	}
+
+	if dataPool, ok := sourceImage.DataPool.(string); imageCopy.DataPool == nil && ok && dataPool != "" {
+		imageCopy.DataPool = dataPool
+	}
+
+	// image level configuration of the source, overridden by the configured one (qos)
+	for _, conf := range sourceImage.Configuration {
+		if conf.Source == rbdConfigSourceImage {
+			imageCopy.Configuration[conf.Name] = conf.Value
+		}
+	}
+
+	for name, value := range rbd.Configuration {
+		imageCopy.Configuration[name] = value
+	}
+
+	log.Printf("[DEBUG] copying rbd image %s to %s", sourceName, ceph.PathJoin(rbd.PoolName, rbd.Namespace, rbd.Name))
+
+	if _, err = client.CopyBlockImage(sourceSpec, imageCopy); err != nil {
+		return err
+	}
+
+	return resizeRBDImage(client, rbd, sourceName)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DataSourceRBD reads an rbd image not managed by terraform.
+func DataSourceRBD() *schema.Resource {
+	dataSource := &schema.Resource{
+		ReadContext: dataSourceRBDRead,
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name_space": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "ceph name space",
+			},
+			"img_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ceph rbd image name",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "ceph rbd image size in bytes",
+			},
+			"features": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "ceph rbd image features",
+			},
+			"object_size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "ceph rbd object size in bytes",
+			},
+			"stripe_unit": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "ceph rbd stripe unit in bytes",
+			},
+			"stripe_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "ceph rbd stripe count",
+			},
+			"data_pool": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "pool the image data is stored in",
+			},
+			"qos": rbdQoSSchema(true),
+			"metadata": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "image metadata (rbd image-meta)",
+			},
+			"owner": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ownership marker (workspace/owner tag) of the image",
+			},
+			"parent": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: rbdImageRefSchema(true)},
+				Description: "parent snapshot of a cloned image",
+			},
+		},
+	}
+
+	for key, attribute := range rbdImageComputedSchema() {
+		dataSource.Schema[key] = attribute
+	}
+
+	return dataSource
+}
+
+func dataSourceRBDRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), d.Get("name_space").(string), d.Get("img_name").(string))
+
+	_, rbd, err := client.GetBlockImage(imageSpec)
+
+	if errors.Is(err, api.ErrNotFound) {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("rbd image %s not found", imageSpec),
+			Detail:   err.Error(),
+		})
+	}
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(rbd.UniqueID)
+
+	dataPool, _ := rbd.DataPool.(string)
+	nameSpace, _ := rbd.Namespace.(string)
+
+	// all metadata keys, none of them is managed here
+	metadata := make(map[string]interface{}, len(rbd.Metadata))
+
+	for key, value := range rbd.Metadata {
+		metadata[key] = value
+	}
+
+	values := map[string]interface{}{
+		"name_space":   nameSpace,
+		"size":         rbd.Size,
+		"features":     rbd.FeaturesName,
+		"object_size":  rbd.ObjSize,
+		"stripe_unit":  rbd.StripeUnit,
+		"stripe_count": rbd.StripeCount,
+		"data_pool":    dataPool,
+		"qos":          flattenRBDQoS(rbd),
+		"metadata":     metadata,
+		"owner":        rbd.Metadata[rbdOwnerMetadataKey],
+		"parent":       flattenRBDParent(rbd.Parent),
+	}
+
+	for key, value := range values {
+		if err = d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err = setRBDImageComputed(d, rbd); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
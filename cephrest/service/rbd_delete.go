@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	deletionPolicyDelete = "delete"
+	deletionPolicyTrash  = "trash"
+)
+
+func validateDuration(v interface{}, k string) (warnings []string, errs []error) {
+	duration, err := time.ParseDuration(v.(string))
+
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid duration '%s': %v", k, v, err)}
+	}
+
+	if duration < 0 {
+		return nil, []error{fmt.Errorf("%s: duration can not be negative", k)}
+	}
+
+	return nil, nil
+}
+
+// removeRBDImage deletes the rbd image or moves it to the trash according to deletion_policy.
+// Images not owned by this workspace are refused.
+func removeRBDImage(cephConf *configuration.Ceph, d *schema.ResourceData, poolName string, nameSpace *string, imgName string) error {
+	client := cephConf.Client
+	imageSpec := ceph.PathJoin(poolName, nameSpace, imgName)
+
+	_, image, err := client.GetBlockImage(imageSpec)
+
+	if errors.Is(err, api.ErrNotFound) {
+		log.Printf("[WARN] rbd image %s already deleted", imageSpec)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = checkRBDOwnership(cephConf, d, imageSpec, image); err != nil {
+		return err
+	}
+
+	if d.Get("deletion_policy").(string) == deletionPolicyTrash {
+		delay, err := time.ParseDuration(d.Get("trash_delay").(string))
+
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] moving rbd image %s to trash (delay %s)", imageSpec, delay)
+
+		_, err = client.MoveBlockImageToTrash(imageSpec, delay)
+
+		return err
+	}
+
+	// ceph fails (after many retries) to delete an image having snapshots, check them up front
+	snapshots, err := api.ImageSnapshots(image.RBD)
+
+	if err != nil {
+		return err
+	}
+
+	if err = checkRBDImageDeletable(imageSpec, snapshots, d.Get("force_delete_snapshots").(bool)); err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		if err = deleteRBDSnapshot(client, imageSpec, snapshot.Name, snapshot.IsProtected); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] deleting rbd image %s", imageSpec)
+
+	// fails on the first failed rbd/delete task (e.g. watchers) instead of retrying
+	_, err = client.DeleteBlockImage(imageSpec)
+
+	return err
+}
+
+// deletionBlockedError is returned if an image can not be deleted because of its snapshots or clones.
+type deletionBlockedError struct {
+	imageSpec string
+	snapshots []string
+	children  []string
+}
+
+func (e *deletionBlockedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.summary(), e.detail())
+}
+
+func (e *deletionBlockedError) summary() string {
+	return fmt.Sprintf("rbd image %s can not be deleted", e.imageSpec)
+}
+
+func (e *deletionBlockedError) detail() string {
+	if len(e.children) > 0 {
+		return fmt.Sprintf("snapshots of the image have clones, flatten or delete them first: %s",
+			strings.Join(e.children, ", "))
+	}
+
+	return fmt.Sprintf("the image has snapshots, delete them first or set force_delete_snapshots = true: %s",
+		strings.Join(e.snapshots, ", "))
+}
+
+// diagnostics returns the error as diagnostics listing the blocking snapshots or clones.
+func (e *deletionBlockedError) diagnostics() diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  e.summary(),
+		Detail:   e.detail(),
+	}}
+}
+
+// checkRBDImageDeletable returns a deletionBlockedError if the image has clones or (unless
+// forceDeleteSnapshots is set) snapshots.
+func checkRBDImageDeletable(imageSpec string, snapshots []api.Snapshot, forceDeleteSnapshots bool) error {
+	blocked := &deletionBlockedError{imageSpec: imageSpec}
+
+	for _, snapshot := range snapshots {
+		blocked.snapshots = append(blocked.snapshots, snapshot.Name)
+
+		for _, child := range snapshot.Children {
+			blocked.children = append(blocked.children, fmt.Sprintf("%s (clone of @%s)", child.Spec(), snapshot.Name))
+		}
+	}
+
+	if len(blocked.children) > 0 || (len(blocked.snapshots) > 0 && !forceDeleteSnapshots) {
+		return blocked
+	}
+
+	return nil
+}
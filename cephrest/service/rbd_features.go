@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rbdFeatures are the feature names ceph reports in RBD.FeaturesName.
+var rbdFeatures = []string{
+	"layering",
+	"striping",
+	"exclusive-lock",
+	"object-map",
+	"fast-diff",
+	"deep-flatten",
+	"journaling",
+	"data-pool",
+	"operations",
+	"migrating",
+	"non-primary",
+}
+
+// rbdFeaturesRequestable are the features which can be requested for an image.
+var rbdFeaturesRequestable = []string{
+	"layering",
+	"exclusive-lock",
+	"object-map",
+	"fast-diff",
+	"deep-flatten",
+	"journaling",
+}
+
+// rbdFeaturesImplicit are features set by ceph itself (e.g. for a data pool or non default striping),
+// they can not be requested and are not part of the features attribute.
+var rbdFeaturesImplicit = map[string]bool{
+	"striping":    true,
+	"data-pool":   true,
+	"operations":  true,
+	"migrating":   true,
+	"non-primary": true,
+}
+
+// explicitFeatures returns features without the ones set by ceph itself.
+func explicitFeatures(features []string) []string {
+	var explicit []string
+
+	for _, feature := range features {
+		if !rbdFeaturesImplicit[feature] {
+			explicit = append(explicit, feature)
+		}
+	}
+
+	return explicit
+}
+
+// rbdFeaturesEnable are the features ceph allows to enable on an existing image.
+var rbdFeaturesEnable = map[string]bool{
+	"exclusive-lock": true,
+	"object-map":     true,
+	"fast-diff":      true,
+	"journaling":     true,
+}
+
+// rbdFeaturesDisable are the features ceph allows to disable on an existing image.
+var rbdFeaturesDisable = map[string]bool{
+	"exclusive-lock": true,
+	"object-map":     true,
+	"fast-diff":      true,
+	"deep-flatten":   true,
+	"journaling":     true,
+}
+
+// resourceRBDFeaturesCustomizeDiff forces a new image if features are changed ceph can not toggle in place.
+func resourceRBDFeaturesCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !rbdFeaturesRequireNew(d) {
+		return nil
+	}
+
+	return d.ForceNew("features")
+}
+
+// rbdFeaturesRequireNew returns true if features are changed ceph can not toggle in place.
+func rbdFeaturesRequireNew(d *schema.ResourceDiff) bool {
+	if !d.HasChange("features") || !d.NewValueKnown("features") {
+		return false
+	}
+
+	o, n := d.GetChange("features")
+	oldFeatures, newFeatures := o.(*schema.Set), n.(*schema.Set)
+
+	for _, f := range newFeatures.Difference(oldFeatures).List() {
+		if rbdFeaturesImplicit[f.(string)] {
+			continue
+		}
+
+		if !rbdFeaturesEnable[f.(string)] {
+			return true
+		}
+	}
+
+	for _, f := range oldFeatures.Difference(newFeatures).List() {
+		// ceph adds e.g. data-pool or striping itself for a data_pool or non default striping
+		if rbdFeaturesImplicit[f.(string)] {
+			continue
+		}
+
+		if !rbdFeaturesDisable[f.(string)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandStringSet converts a set of strings to a string slice (nil for an empty set).
+func expandStringSet(v interface{}) []string {
+	var list []string
+
+	set, ok := v.(*schema.Set)
+	if !ok {
+		return nil
+	}
+
+	for _, s := range set.List() {
+		list = append(list, s.(string))
+	}
+
+	return list
+}
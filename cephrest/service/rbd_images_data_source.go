@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// rbdListStatusOK is the RBDList status of a pool listed without errors.
+const rbdListStatusOK = 0
+
+// rbdListStatusText describes the RBDList status values (view cache states of the dashboard).
+var rbdListStatusText = map[int]string{
+	1: "stale",
+	2: "no data",
+	3: "exception",
+}
+
+// DataSourceRBDImages lists the rbd images of a pool (or of all pools) matching the filters.
+func DataSourceRBDImages() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRBDImagesRead,
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "pool to list the images of (all pools if not set)",
+			},
+			"name_space": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "only list images of this ceph name space (\"\" for the default name space)",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "only list images with a name matching the regular expression",
+			},
+			"min_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "only list images with at least this size in bytes",
+			},
+			"max_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "only list images with at most this size in bytes",
+			},
+			"features": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(rbdFeatures, false)},
+				Set:         schema.HashString,
+				Description: "only list images having all of these features",
+			},
+			"pools": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"pool_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "listing status of the pool (0 = ok)",
+						},
+					},
+				},
+				Description: "listed pools and their listing status",
+			},
+			"images": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"pool_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name_space": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"img_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"unique_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"features": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"data_pool": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"disk_usage": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"total_disk_usage": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+				Description: "images matching the filters",
+			},
+		},
+	}
+}
+
+func dataSourceRBDImagesRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+	nameSpace := d.Get("name_space").(string)
+	// name_space = "" filters the default name space, GetOk can not tell it from not set
+	filterNameSpace := !d.GetRawConfig().IsNull() && !d.GetRawConfig().GetAttr("name_space").IsNull()
+	minSize := int64(d.Get("min_size").(int))
+	maxSize, filterMaxSize := d.GetOk("max_size")
+	features := expandStringSet(d.Get("features"))
+
+	var nameRegex *regexp.Regexp
+
+	if expr, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(expr.(string))
+	}
+
+	_, rbdList, err := client.ListBlockImage(poolName)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pools := make([]interface{}, 0, len(rbdList))
+	images := make([]interface{}, 0)
+
+	for _, pool := range rbdList {
+		pools = append(pools, map[string]interface{}{
+			"pool_name": pool.PoolName,
+			"status":    pool.Status,
+		})
+
+		if pool.Status != rbdListStatusOK {
+			status, ok := rbdListStatusText[pool.Status]
+			if !ok {
+				status = "unknown"
+			}
+
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("images of pool %s could not be listed completely", pool.PoolName),
+				Detail:   fmt.Sprintf("listing status %d (%s), the images of the pool may be missing or outdated", pool.Status, status),
+			})
+		}
+
+	images:
+		for _, rbd := range pool.Value {
+			ns, _ := rbd.Namespace.(string)
+
+			switch {
+			case filterNameSpace && ns != nameSpace,
+				nameRegex != nil && !nameRegex.MatchString(rbd.Name),
+				rbd.Size < minSize,
+				filterMaxSize && rbd.Size > int64(maxSize.(int)):
+				continue
+			}
+
+			imageFeatures := make(map[string]bool, len(rbd.FeaturesName))
+			for _, feature := range rbd.FeaturesName {
+				imageFeatures[feature] = true
+			}
+
+			for _, feature := range features {
+				if !imageFeatures[feature] {
+					continue images
+				}
+			}
+
+			dataPool, _ := rbd.DataPool.(string)
+
+			images = append(images, map[string]interface{}{
+				"pool_name":        rbd.PoolName,
+				"name_space":       ns,
+				"img_name":         rbd.Name,
+				"unique_id":        rbd.UniqueID,
+				"size":             rbd.Size,
+				"features":         rbd.FeaturesName,
+				"data_pool":        dataPool,
+				"disk_usage":       rbd.DiskUsage,
+				"total_disk_usage": rbd.TotalDiskUsage,
+			})
+		}
+	}
+
+	if err = d.Set("pools", pools); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("images", images); err != nil {
+		return diag.FromErr(err)
+	}
+
+	sort.Strings(features)
+
+	d.SetId(strings.Join([]string{
+		poolName,
+		d.Get("name_space").(string),
+		d.Get("name_regex").(string),
+		fmt.Sprint(minSize),
+		fmt.Sprint(d.Get("max_size").(int)),
+		strings.Join(features, ","),
+	}, "|"))
+
+	return diags
+}
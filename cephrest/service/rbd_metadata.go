@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func validateRBDMetadata(v interface{}, k string) (warnings []string, errs []error) {
+	if _, ok := v.(map[string]interface{})[rbdOwnerMetadataKey]; ok {
+		return nil, []error{fmt.Errorf("%s: key %s is reserved for the ownership marker", k, rbdOwnerMetadataKey)}
+	}
+
+	return nil, nil
+}
+
+// expandRBDMetadataChange returns the metadata keys to set and (with value nil) to remove.
+func expandRBDMetadataChange(d *schema.ResourceData) map[string]interface{} {
+	o, n := d.GetChange("metadata")
+	oldMetadata, newMetadata := o.(map[string]interface{}), n.(map[string]interface{})
+
+	metadata := make(map[string]interface{}, len(oldMetadata)+len(newMetadata))
+
+	for key := range oldMetadata {
+		if _, ok := newMetadata[key]; !ok {
+			metadata[key] = nil
+		}
+	}
+
+	for key, value := range newMetadata {
+		metadata[key] = value
+	}
+
+	return metadata
+}
+
+// flattenRBDMetadata returns the image metadata of the keys managed by terraform (the ones in state),
+// keys set by other tools are left out.
+func flattenRBDMetadata(d *schema.ResourceData, image api.Image) map[string]interface{} {
+	metadata := make(map[string]interface{})
+
+	for key := range d.Get("metadata").(map[string]interface{}) {
+		if value, ok := image.Metadata[key]; ok {
+			metadata[key] = value
+		}
+	}
+
+	return metadata
+}
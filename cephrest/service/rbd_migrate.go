@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceRBDPoolCustomizeDiff forces a new image on pool or name space changes unless
+// migrate_on_pool_change is set.
+func resourceRBDPoolCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || d.Get("migrate_on_pool_change").(bool) {
+		return nil
+	}
+
+	for _, key := range []string{"pool_name", "name_space"} {
+		if d.HasChange(key) {
+			if err := d.ForceNew(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateRBDImage copies the image to its new pool / name space (with its new name), verifies the copy
+// and removes the source according to deletion_policy. Images with snapshots are refused as the copy
+// does not carry them. Once the copy is verified the state follows it, a source which can not be removed
+// is returned as warning.
+func migrateRBDImage(cephConf *configuration.Ceph, d *schema.ResourceData) (diag.Diagnostics, error) {
+	client := cephConf.Client
+
+	oldPool, newPool := d.GetChange("pool_name")
+	oldNameSpace, newNameSpace := d.GetChange("name_space")
+	oldImgName, newImgName := d.GetChange("img_name")
+
+	sourceSpec := ceph.PathJoin(oldPool.(string), oldNameSpace.(string), oldImgName.(string))
+	targetSpec := ceph.PathJoin(newPool.(string), newNameSpace.(string), newImgName.(string))
+
+	_, source, err := client.GetBlockImage(sourceSpec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// check up front the source can be removed, so no copy is left behind
+	if err = checkRBDOwnership(cephConf, d, sourceSpec, source); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := api.ImageSnapshots(source.RBD)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) > 0 {
+		var names []string
+
+		for _, snapshot := range snapshots {
+			names = append(names, snapshot.Name)
+		}
+
+		return nil, fmt.Errorf("rbd image %s can not be migrated to %s: snapshots are not copied, delete them first: %s",
+			sourceSpec, targetSpec, strings.Join(names, ", "))
+	}
+
+	target := api.ImageCreate{
+		PoolName:  newPool.(string),
+		Namespace: nameSpacePtr(d),
+		Name:      newImgName.(string),
+		Metadata:  rbdOwnerMetadata(cephConf, d),
+	}
+
+	// managed metadata keys are carried over, metadata is not changed in the update following the migration
+	for key, value := range d.Get("metadata").(map[string]interface{}) {
+		target.Metadata[key] = value
+	}
+
+	log.Printf("[DEBUG] migrating rbd image %s to %s", sourceSpec, targetSpec)
+
+	if err = copyRBDImageFrom(client, sourceSpec, "", target); err != nil {
+		if imageAlreadyExists(err) {
+			// not our copy
+			return nil, err
+		}
+
+		return nil, discardRBDImage(client, targetSpec, rbdOwner(cephConf, d), err)
+	}
+
+	_, copied, err := client.GetBlockImage(targetSpec)
+
+	if err != nil {
+		return nil, discardRBDImage(client, targetSpec, rbdOwner(cephConf, d), err)
+	}
+
+	sourceFeatures := explicitFeatures(source.FeaturesName)
+	copiedFeatures := explicitFeatures(copied.FeaturesName)
+	sort.Strings(sourceFeatures)
+	sort.Strings(copiedFeatures)
+
+	if copied.Size != source.Size || strings.Join(sourceFeatures, ",") != strings.Join(copiedFeatures, ",") {
+		return nil, discardRBDImage(client, targetSpec, rbdOwner(cephConf, d), fmt.Errorf("migration of rbd image %s to %s failed verification, "+
+			"source is kept: source size %d, features [%s] - copy size %d, features [%s]", sourceSpec, targetSpec,
+			source.Size, strings.Join(sourceFeatures, ", "), copied.Size, strings.Join(copiedFeatures, ", ")))
+	}
+
+	// the copy is a new image with its own unique id
+	d.SetId(copied.UniqueID)
+
+	var oldNameSpacePtr *string
+
+	if ns := oldNameSpace.(string); ns != "" {
+		oldNameSpacePtr = &ns
+	}
+
+	if err = removeRBDImage(cephConf, d, oldPool.(string), oldNameSpacePtr, oldImgName.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("rbd image %s migrated to %s, but the source could not be removed", sourceSpec, targetSpec),
+			Detail:   fmt.Sprintf("remove the source by hand: %v", err),
+		}}, nil
+	}
+
+	return nil, nil
+}
+
+// discardRBDImage deletes the copy imageSpec of a failed migration and returns err. The image is only
+// deleted if it carries the ownership marker owner written by the copy, an image existing before the
+// copy (or without a copy started) is kept.
+func discardRBDImage(client *api.Client, imageSpec, owner string, err error) error {
+	_, image, getErr := client.GetBlockImage(imageSpec)
+
+	if errors.Is(getErr, api.ErrNotFound) {
+		return err
+	}
+
+	if getErr != nil {
+		return fmt.Errorf("%w (copy %s could not be checked for removal: %v)", err, imageSpec, getErr)
+	}
+
+	if image.Metadata[rbdOwnerMetadataKey] != owner {
+		log.Printf("[WARN] keeping rbd image %s not created by the failed migration (owner '%s')",
+			imageSpec, image.Metadata[rbdOwnerMetadataKey])
+		return err
+	}
+
+	log.Printf("[WARN] removing rbd image %s of failed migration", imageSpec)
+
+	if _, deleteErr := client.DeleteBlockImage(imageSpec); deleteErr != nil && !errors.Is(deleteErr, api.ErrNotFound) {
+		return fmt.Errorf("%w (copy %s could not be removed: %v)", err, imageSpec, deleteErr)
+	}
+
+	return err
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const mirrorModeDisabled = "disabled"
+
+func ResourceRBDMirrorPool() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRBDMirrorPoolCreate,
+		ReadContext:   resourceRBDMirrorPoolRead,
+		DeleteContext: resourceRBDMirrorPoolDelete,
+		UpdateContext: resourceRBDMirrorPoolUpdate,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRBDMirrorPoolImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"mirror_mode": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{mirrorModeDisabled, "pool", "image"}, false),
+				Description:  "disabled, pool (mirror all images with journaling) or image (mirror explicitly enabled images)",
+			},
+			"site_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "local site name (cluster wide, shared by all pools)",
+			},
+		},
+	}
+}
+
+func resourceRBDMirrorPoolRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	_, mirrorPool, err := client.GetBlockMirroringPool(poolName)
+
+	if errors.Is(err, api.ErrNotFound) {
+		// pool was deleted outside of terraform
+		log.Printf("[WARN] pool %s not found, removing mirroring from state", poolName)
+		d.SetId("")
+		return diags
+	}
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, siteName, err := client.GetBlockMirroringSiteName()
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(poolName)
+
+	if err = d.Set("mirror_mode", mirrorPool.MirrorMode); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("site_name", siteName.SiteName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceRBDMirrorPoolCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("pool_name").(string))
+
+	return resourceRBDMirrorPoolUpdate(ctx, d, meta)
+}
+
+func resourceRBDMirrorPoolUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	if siteName, ok := d.GetOk("site_name"); ok && d.HasChange("site_name") {
+		log.Printf("[DEBUG] setting rbd mirroring site name %s", siteName)
+
+		if _, err := client.UpdateBlockMirroringSiteName(api.SiteName{SiteName: siteName.(string)}); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("mirror_mode") {
+		log.Printf("[DEBUG] setting rbd mirror mode of pool %s to %s", poolName, d.Get("mirror_mode"))
+
+		_, err := client.UpdateBlockMirroringPool(poolName, api.MirrorPool{MirrorMode: d.Get("mirror_mode").(string)})
+
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceRBDMirrorPoolRead(ctx, d, meta)
+}
+
+func resourceRBDMirrorPoolDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	log.Printf("[DEBUG] disabling rbd mirroring of pool %s", poolName)
+
+	_, err := client.UpdateBlockMirroringPool(poolName, api.MirrorPool{MirrorMode: mirrorModeDisabled})
+
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// resourceRBDMirrorPoolImport imports the mirroring configuration of a pool by the pool name.
+func resourceRBDMirrorPoolImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set("pool_name", d.Id()); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
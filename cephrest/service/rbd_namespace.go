@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceRBDNamespace() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRBDNamespaceCreate,
+		ReadContext:   resourceRBDNamespaceRead,
+		DeleteContext: resourceRBDNamespaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRBDNamespaceImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name_space": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ceph name space",
+			},
+			"num_images": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of rbd images in the name space",
+			},
+		},
+	}
+}
+
+func resourceRBDNamespaceRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	_, namespace, err := client.GetBlockPoolNamespace(poolName, d.Get("name_space").(string))
+
+	if errors.Is(err, api.ErrNotFound) {
+		// name space (or its pool) was deleted outside of terraform -> plan re-create
+		log.Printf("[WARN] rbd namespace %s/%s not found, removing from state", poolName, d.Get("name_space").(string))
+		d.SetId("")
+		return diags
+	}
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", poolName, namespace.Namespace))
+
+	if err = d.Set("name_space", namespace.Namespace); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("num_images", namespace.NumImages); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceRBDNamespaceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+	nameSpace := d.Get("name_space").(string)
+
+	log.Printf("[DEBUG] creating rbd namespace %s/%s", poolName, nameSpace)
+
+	if _, err := client.CreateBlockPoolNamespace(poolName, nameSpace); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", poolName, nameSpace))
+
+	return resourceRBDNamespaceRead(ctx, d, meta)
+}
+
+func resourceRBDNamespaceDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+	nameSpace := d.Get("name_space").(string)
+
+	_, namespace, err := client.GetBlockPoolNamespace(poolName, nameSpace)
+
+	if errors.Is(err, api.ErrNotFound) {
+		d.SetId("")
+		return diags
+	}
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if namespace.NumImages > 0 {
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("rbd namespace %s/%s is not empty", poolName, nameSpace),
+			Detail:   fmt.Sprintf("the name space still contains %d images, delete them before deleting the name space", namespace.NumImages),
+		})
+	}
+
+	log.Printf("[DEBUG] deleting rbd namespace %s/%s", poolName, nameSpace)
+
+	if _, err = client.DeleteBlockPoolNamespace(poolName, nameSpace); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// resourceRBDNamespaceImport imports an existing rbd namespace by "pool/namespace".
+func resourceRBDNamespaceImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	spec := d.Id()
+	parts := strings.Split(spec, "/")
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid namespace spec '%s': expected pool/namespace", spec)
+	}
+
+	if err := d.Set("pool_name", parts[0]); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("name_space", parts[1]); err != nil {
+		return nil, err
+	}
+
+	if diags := resourceRBDNamespaceRead(ctx, d, meta); diags.HasError() {
+		return nil, diagsToError(diags)
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("rbd namespace %s: %w", spec, api.ErrNotFound)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
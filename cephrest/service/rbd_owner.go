@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rbdOwnerMetadataKey is the image metadata key holding the ownership marker "workspace/owner tag".
+const rbdOwnerMetadataKey = "terraform-provider-ceph-rest.owner"
+
+// rbdOwnerSource is implemented by schema.ResourceData and schema.ResourceDiff.
+type rbdOwnerSource interface {
+	Get(key string) interface{}
+	GetOk(key string) (interface{}, bool)
+}
+
+// rbdOwner returns the ownership marker of the image: provider workspace and owner tag (owner_tag of
+// the image overrides the one of the provider).
+func rbdOwner(cephConf *configuration.Ceph, d rbdOwnerSource) string {
+	ownerTag := cephConf.OwnerTag
+
+	if tag, ok := d.GetOk("owner_tag"); ok {
+		ownerTag = tag.(string)
+	}
+
+	return fmt.Sprintf("%s/%s", cephConf.Workspace, ownerTag)
+}
+
+// rbdOwnerMetadata returns the image metadata to write the ownership marker.
+func rbdOwnerMetadata(cephConf *configuration.Ceph, d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{rbdOwnerMetadataKey: rbdOwner(cephConf, d)}
+}
+
+// resourceRBDOwnerCustomizeDiff plans a new ownership marker if it differs from the one in state, e.g.
+// on a changed owner_tag or for images without marker. A marker of another workspace is rejected.
+func resourceRBDOwnerCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	cephConf, ok := meta.(*configuration.Ceph)
+
+	if d.Id() == "" || !ok {
+		return nil
+	}
+
+	owner := d.Get("owner").(string)
+
+	if expected := rbdOwner(cephConf, d); owner != expected {
+		imageSpec := ceph.PathJoin(d.Get("pool_name").(string), d.Get("name_space").(string), d.Get("img_name").(string))
+
+		if err := checkRBDOwnerTakeover(cephConf, d, imageSpec, owner); err != nil {
+			return err
+		}
+
+		return d.SetNew("owner", expected)
+	}
+
+	return nil
+}
+
+// checkRBDOwnerTakeover returns an ownershipError if the marker owner can not be replaced by the one
+// of this workspace: only a missing marker or one of this workspace (with another owner tag) is
+// replaced, unless the check is overridden on provider or image level.
+func checkRBDOwnerTakeover(cephConf *configuration.Ceph, d rbdOwnerSource, imageSpec, owner string) error {
+	if owner == "" || cephConf.IgnoreOwnership || d.Get("ignore_ownership").(bool) {
+		return nil
+	}
+
+	if workspace := strings.SplitN(owner, "/", 2)[0]; workspace == cephConf.Workspace {
+		return nil
+	}
+
+	return &ownershipError{imageSpec: imageSpec, owner: owner, expected: rbdOwner(cephConf, d)}
+}
+
+// ownershipError is returned if an image is not owned by this workspace.
+type ownershipError struct {
+	imageSpec string
+	owner     string
+	expected  string
+}
+
+func (e *ownershipError) Error() string {
+	return fmt.Sprintf("%s: %s", e.summary(), e.detail())
+}
+
+func (e *ownershipError) summary() string {
+	return fmt.Sprintf("rbd image %s is not owned by this workspace", e.imageSpec)
+}
+
+func (e *ownershipError) detail() string {
+	if e.owner == "" {
+		return fmt.Sprintf("the image has no ownership marker (metadata key %s), expected '%s'; "+
+			"set ignore_ownership = true to act on it anyway", rbdOwnerMetadataKey, e.expected)
+	}
+
+	return fmt.Sprintf("the image is owned by '%s', expected '%s'; set ignore_ownership = true to act on it anyway",
+		e.owner, e.expected)
+}
+
+// diagnostics returns the error as diagnostics naming the current and expected owner.
+func (e *ownershipError) diagnostics() diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  e.summary(),
+		Detail:   e.detail(),
+	}}
+}
+
+// checkRBDOwnership returns an ownershipError if the image marker is missing or differs from the
+// expected one, unless the check is overridden on provider or image level.
+func checkRBDOwnership(cephConf *configuration.Ceph, d *schema.ResourceData, imageSpec string, image api.Image) error {
+	if cephConf.IgnoreOwnership || d.Get("ignore_ownership").(bool) {
+		return nil
+	}
+
+	expected := rbdOwner(cephConf, d)
+
+	if owner := image.Metadata[rbdOwnerMetadataKey]; owner != expected {
+		return &ownershipError{imageSpec: imageSpec, owner: owner, expected: expected}
+	}
+
+	return nil
+}
+
+// adoptRBDImage checks the ownership of an already existing image taken over into state on create
+// (adopt_existing). An image adopted with ignore_ownership gets the ownership marker of this workspace.
+func adoptRBDImage(cephConf *configuration.Ceph, d *schema.ResourceData) error {
+	client := cephConf.Client
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), d.Get("name_space").(string), d.Get("img_name").(string))
+
+	_, image, err := client.GetBlockImage(imageSpec)
+
+	if err != nil {
+		return err
+	}
+
+	if err = checkRBDOwnership(cephConf, d, imageSpec, image); err != nil {
+		return err
+	}
+
+	owner := rbdOwner(cephConf, d)
+
+	if image.Metadata[rbdOwnerMetadataKey] == owner {
+		return nil
+	}
+
+	log.Printf("[DEBUG] adopting rbd image %s (owner '%s')", imageSpec, image.Metadata[rbdOwnerMetadataKey])
+
+	_, err = client.UpdateBlockImage(imageSpec, api.ImageUpdate{
+		Name:     image.Name,
+		Size:     image.Size,
+		Metadata: rbdOwnerMetadata(cephConf, d),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return d.Set("owner", owner)
+}
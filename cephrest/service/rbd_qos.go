@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// rbdConfigSourceImage is the RBD.Configuration source of options set on the image itself.
+const rbdConfigSourceImage = 2
+
+// rbdQoSOptions maps the qos block attributes to the ceph rbd configuration options.
+var rbdQoSOptions = map[string]string{
+	"iops_limit":       "rbd_qos_iops_limit",
+	"iops_burst":       "rbd_qos_iops_burst",
+	"bps_limit":        "rbd_qos_bps_limit",
+	"bps_burst":        "rbd_qos_bps_burst",
+	"read_iops_limit":  "rbd_qos_read_iops_limit",
+	"read_iops_burst":  "rbd_qos_read_iops_burst",
+	"write_iops_limit": "rbd_qos_write_iops_limit",
+	"write_iops_burst": "rbd_qos_write_iops_burst",
+	"read_bps_limit":   "rbd_qos_read_bps_limit",
+	"read_bps_burst":   "rbd_qos_read_bps_burst",
+	"write_bps_limit":  "rbd_qos_write_bps_limit",
+	"write_bps_burst":  "rbd_qos_write_bps_burst",
+}
+
+// rbdQoSSchema returns the qos block of the resource or (computed) of the data source.
+func rbdQoSSchema(computed bool) *schema.Schema {
+	attributes := make(map[string]*schema.Schema, len(rbdQoSOptions))
+
+	for attribute, option := range rbdQoSOptions {
+		attributes[attribute] = &schema.Schema{
+			Type:        schema.TypeInt,
+			Optional:    !computed,
+			Computed:    computed,
+			Description: option + " (0 = unlimited)",
+		}
+
+		if !computed {
+			attributes[attribute].ValidateFunc = validation.IntAtLeast(0)
+		}
+	}
+
+	qos := &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    !computed,
+		Computed:    computed,
+		Elem:        &schema.Resource{Schema: attributes},
+		Description: "per image qos limits",
+	}
+
+	// MaxItems is for configurable attributes only
+	if !computed {
+		qos.MaxItems = 1
+	}
+
+	return qos
+}
+
+// expandRBDQoS returns the rbd configuration options of the qos block. Unset (0) limits are
+// returned as nil if withRemovals is set, so ceph removes them from the image.
+func expandRBDQoS(v interface{}, withRemovals bool) map[string]interface{} {
+	configuration := make(map[string]interface{})
+
+	var qos map[string]interface{}
+
+	if list, ok := v.([]interface{}); ok && len(list) > 0 && list[0] != nil {
+		qos = list[0].(map[string]interface{})
+	}
+
+	for attribute, option := range rbdQoSOptions {
+		value, _ := qos[attribute].(int)
+
+		switch {
+		case value > 0:
+			configuration[option] = value
+		case withRemovals:
+			configuration[option] = nil
+		}
+	}
+
+	return configuration
+}
+
+// flattenRBDQoS returns the qos block from the image level configuration of rbd.
+func flattenRBDQoS(rbd api.Image) []interface{} {
+	qos := make(map[string]interface{})
+
+	for _, conf := range rbd.Configuration {
+		if conf.Source != rbdConfigSourceImage {
+			continue
+		}
+
+		for attribute, option := range rbdQoSOptions {
+			if conf.Name != option {
+				continue
+			}
+
+			if value, err := strconv.Atoi(conf.Value); err == nil && value > 0 {
+				qos[attribute] = value
+			}
+		}
+	}
+
+	if len(qos) == 0 {
+		return []interface{}{}
+	}
+
+	return []interface{}{qos}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rbdForceNewKeys are the attributes replacing the image on change (besides pool and features).
+var rbdForceNewKeys = []string{"object_size", "stripe_unit", "stripe_count", "data_pool", "source_snapshot", "source_image"}
+
+// resourceRBDSizeCustomizeDiff rejects shrinking an image (ceph truncates it) unless allow_shrink is set.
+// A smaller size of an image which is replaced anyway is accepted.
+func resourceRBDSizeCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("size") || !d.NewValueKnown("size") || rbdRequiresNew(d) {
+		return nil
+	}
+
+	o, n := d.GetChange("size")
+	oldSize, newSize := o.(int), n.(int)
+
+	if newSize >= oldSize || d.Get("allow_shrink").(bool) {
+		return nil
+	}
+
+	return fmt.Errorf("shrinking rbd image %s from %d to %d bytes truncates the image and destroys its data: "+
+		"set allow_shrink = true to shrink it anyway", d.Get("img_name").(string), oldSize, newSize)
+}
+
+// rbdRequiresNew returns true if the planned changes replace the image.
+func rbdRequiresNew(d *schema.ResourceDiff) bool {
+	if !d.Get("migrate_on_pool_change").(bool) && (d.HasChange("pool_name") || d.HasChange("name_space")) {
+		return true
+	}
+
+	for _, key := range rbdForceNewKeys {
+		if d.HasChange(key) {
+			return true
+		}
+	}
+
+	return rbdFeaturesRequireNew(d)
+}
+
+// sizeUnits maps the (upper case) unit suffixes accepted by size_human to their factor.
+// Single letter units are binary like for the rbd cli (500G = 500GiB).
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KIB": 1 << 10,
+	"KB":  1000,
+	"M":   1 << 20,
+	"MIB": 1 << 20,
+	"MB":  1000 * 1000,
+	"G":   1 << 30,
+	"GIB": 1 << 30,
+	"GB":  1000 * 1000 * 1000,
+	"T":   1 << 40,
+	"TIB": 1 << 40,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"P":   1 << 50,
+	"PIB": 1 << 50,
+	"PB":  1000 * 1000 * 1000 * 1000 * 1000,
+}
+
+var sizeRegexp = regexp.MustCompile(`^\s*(\d+)\s*([A-Za-z]*)\s*$`)
+
+// parseSize converts a human readable size like 10GiB, 500G or 2TiB to bytes.
+func parseSize(size string) (int64, error) {
+	match := sizeRegexp.FindStringSubmatch(size)
+
+	if match == nil {
+		return 0, fmt.Errorf("invalid size '%s': expected a number followed by an optional unit (e.g. 10GiB, 500G, 2TiB)", size)
+	}
+
+	factor, ok := sizeUnits[strings.ToUpper(match[2])]
+
+	if !ok {
+		return 0, fmt.Errorf("invalid size '%s': unknown unit '%s'", size, match[2])
+	}
+
+	value, err := strconv.ParseInt(match[1], 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': %v", size, err)
+	}
+
+	if value > math.MaxInt64/factor {
+		return 0, fmt.Errorf("invalid size '%s': too large", size)
+	}
+
+	return value * factor, nil
+}
+
+func validateSize(v interface{}, k string) (warnings []string, errs []error) {
+	size, err := parseSize(v.(string))
+
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %v", k, err)}
+	}
+
+	if size <= 0 {
+		return nil, []error{fmt.Errorf("%s: size must be greater than 0", k)}
+	}
+
+	return nil, nil
+}
+
+// suppressSizeHumanDiff suppresses changes of size_human not changing the size in bytes (e.g. 10GiB -> 10240MiB).
+func suppressSizeHumanDiff(_, _, n string, d *schema.ResourceData) bool {
+	if d.Id() == "" || n == "" {
+		return false
+	}
+
+	size, err := parseSize(n)
+
+	return err == nil && size == int64(d.Get("size").(int))
+}
+
+// resourceRBDSizeHumanCustomizeDiff plans the size in bytes for a configured size_human.
+func resourceRBDSizeHumanCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	sizeHuman, ok := d.GetOk("size_human")
+
+	if !ok || !d.NewValueKnown("size_human") {
+		return nil
+	}
+
+	size, err := parseSize(sizeHuman.(string))
+
+	if err != nil {
+		return err
+	}
+
+	if d.Id() != "" && int64(d.Get("size").(int)) == size {
+		return nil
+	}
+
+	return d.SetNew("size", size)
+}
+
+// rbdSize returns the configured image size in bytes from size or size_human.
+func rbdSize(d *schema.ResourceData) (int64, error) {
+	if sizeHuman, ok := d.GetOk("size_human"); ok {
+		return parseSize(sizeHuman.(string))
+	}
+
+	return int64(d.Get("size").(int)), nil
+}
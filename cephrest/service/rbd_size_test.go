@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{size: "1073741824", want: 1 << 30},
+		{size: "512B", want: 512},
+		{size: "1K", want: 1 << 10},
+		{size: "1KiB", want: 1 << 10},
+		{size: "1KB", want: 1000},
+		{size: "10GiB", want: 10 << 30},
+		{size: "500G", want: 500 << 30},
+		{size: "500GB", want: 500 * 1000 * 1000 * 1000},
+		{size: "2TiB", want: 2 << 40},
+		{size: "2TB", want: 2 * 1000 * 1000 * 1000 * 1000},
+		{size: "1PiB", want: 1 << 50},
+		{size: "10240MiB", want: 10 << 30},
+		{size: "10gib", want: 10 << 30},
+		{size: "10Gib", want: 10 << 30},
+		{size: " 10 GiB ", want: 10 << 30},
+		{size: "10\tGiB", want: 10 << 30},
+		{size: "8191PiB", want: 8191 << 50},
+		{size: "8192PiB", wantErr: true},
+		{size: "9223372036854775807", want: 9223372036854775807},
+		{size: "9223372036854775808", wantErr: true},
+		{size: "99999999999999999999GiB", wantErr: true},
+		{size: "", wantErr: true},
+		{size: "GiB", wantErr: true},
+		{size: "-1GiB", wantErr: true},
+		{size: "1.5GiB", wantErr: true},
+		{size: "10XiB", wantErr: true},
+		{size: "10 Gi B", wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := parseSize(c.size)
+
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseSize(%q) = %d, expected an error", c.size, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseSize(%q) unexpected error: %v", c.size, err)
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("parseSize(%q) = %d, expected %d", c.size, got, c.want)
+		}
+	}
+}
+
+func TestSuppressSizeHumanDiff(t *testing.T) {
+	cases := []struct {
+		id       string
+		size     int
+		new      string
+		suppress bool
+	}{
+		{id: "id", size: 10 << 30, new: "10240MiB", suppress: true},
+		{id: "id", size: 10 << 30, new: "10GiB", suppress: true},
+		{id: "id", size: 10 << 30, new: "10G", suppress: true},
+		{id: "id", size: 10 << 30, new: "10GB", suppress: false},
+		{id: "id", size: 10 << 30, new: "11GiB", suppress: false},
+		{id: "id", size: 10 << 30, new: "", suppress: false},
+		{id: "id", size: 10 << 30, new: "invalid", suppress: false},
+		{id: "", size: 10 << 30, new: "10GiB", suppress: false},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, ResourceRBD().Schema, map[string]interface{}{})
+		d.SetId(c.id)
+
+		if err := d.Set("size", c.size); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := suppressSizeHumanDiff("size_human", "10GiB", c.new, d); got != c.suppress {
+			t.Errorf("suppressSizeHumanDiff(id %q, size %d, new %q) = %v, expected %v", c.id, c.size, c.new, got, c.suppress)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chrisamti/ceph-rest-client/ceph"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/api"
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func ResourceRBDSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRBDSnapshotCreate,
+		ReadContext:   resourceRBDSnapshotRead,
+		DeleteContext: resourceRBDSnapshotDelete,
+		UpdateContext: resourceRBDSnapshotUpdate,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRBDSnapshotImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name_space": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "ceph name space",
+			},
+			"img_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ceph rbd image name",
+			},
+			"snap_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "snapshot name (renamed in place on change)",
+			},
+			"is_protected": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "protect the snapshot from deletion (needed to clone it)",
+			},
+			"snap_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "rbd snapshot id",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "image size in bytes at the time of the snapshot",
+			},
+			"timestamp": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "creation time of the snapshot",
+			},
+		},
+	}
+}
+
+func resourceRBDSnapshotRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), d.Get("name_space").(string), d.Get("img_name").(string))
+
+	_, snapshot, err := client.GetBlockImageSnapshot(imageSpec, d.Get("snap_name").(string))
+
+	if errors.Is(err, api.ErrNotFound) {
+		// image or snapshot was deleted outside of terraform -> plan re-create
+		log.Printf("[WARN] rbd snapshot %s@%s not found, removing from state", imageSpec, d.Get("snap_name").(string))
+		d.SetId("")
+		return diags
+	}
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s@%s", imageSpec, snapshot.Name))
+
+	if err = d.Set("snap_name", snapshot.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("is_protected", snapshot.IsProtected); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("snap_id", snapshot.ID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("size", snapshot.Size); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("timestamp", snapshot.Timestamp); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceRBDSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), nameSpacePtr(d), d.Get("img_name").(string))
+	snapName := d.Get("snap_name").(string)
+
+	log.Printf("[DEBUG] creating rbd snapshot %s@%s", imageSpec, snapName)
+
+	if _, err := client.CreateBlockImageSnapshot(imageSpec, snapName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s@%s", imageSpec, snapName))
+
+	if d.Get("is_protected").(bool) {
+		isProtected := true
+
+		if _, err := client.UpdateBlockImageSnapshot(imageSpec, snapName, api.SnapshotUpdate{IsProtected: &isProtected}); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceRBDSnapshotRead(ctx, d, meta)
+}
+
+func resourceRBDSnapshotUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), nameSpacePtr(d), d.Get("img_name").(string))
+
+	// the snapshot is addressed by its current name, a new name renames it in place.
+	o, n := d.GetChange("snap_name")
+	oldSnapName, newSnapName := o.(string), n.(string)
+
+	var snapshotUpdate api.SnapshotUpdate
+
+	if d.HasChange("snap_name") {
+		snapshotUpdate.NewSnapName = &newSnapName
+	}
+
+	if d.HasChange("is_protected") {
+		isProtected := d.Get("is_protected").(bool)
+		snapshotUpdate.IsProtected = &isProtected
+	}
+
+	log.Printf("[DEBUG] updating rbd snapshot %s@%s", imageSpec, oldSnapName)
+
+	if _, err := client.UpdateBlockImageSnapshot(imageSpec, oldSnapName, snapshotUpdate); err != nil {
+		// keep the old values (e.g. snap_name) in state
+		d.Partial(true)
+		return diag.FromErr(err)
+	}
+
+	return resourceRBDSnapshotRead(ctx, d, meta)
+}
+
+func resourceRBDSnapshotDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := ceph.PathJoin(d.Get("pool_name").(string), nameSpacePtr(d), d.Get("img_name").(string))
+	snapName := d.Get("snap_name").(string)
+
+	if err := deleteRBDSnapshot(client, imageSpec, snapName, d.Get("is_protected").(bool)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// deleteRBDSnapshot deletes the snapshot, a protected snapshot is unprotected first.
+func deleteRBDSnapshot(client *api.Client, imageSpec, snapName string, isProtected bool) error {
+	if isProtected {
+		log.Printf("[DEBUG] unprotecting rbd snapshot %s@%s", imageSpec, snapName)
+
+		unprotect := false
+
+		if _, err := client.UpdateBlockImageSnapshot(imageSpec, snapName, api.SnapshotUpdate{IsProtected: &unprotect}); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] deleting rbd snapshot %s@%s", imageSpec, snapName)
+
+	_, err := client.DeleteBlockImageSnapshot(imageSpec, snapName)
+
+	return err
+}
+
+// resourceRBDSnapshotImport imports an existing rbd snapshot by its spec "pool[/namespace]/image@snapshot".
+func resourceRBDSnapshotImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	snapSpec := d.Id()
+	idx := strings.LastIndex(snapSpec, "@")
+
+	if idx <= 0 || idx == len(snapSpec)-1 {
+		return nil, fmt.Errorf("invalid snapshot spec '%s': expected pool[/namespace]/image@snapshot", snapSpec)
+	}
+
+	poolName, nameSpace, imgName, err := parseImageSpec(snapSpec[:idx])
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"pool_name":  poolName,
+		"name_space": nameSpace,
+		"img_name":   imgName,
+		"snap_name":  snapSpec[idx+1:],
+	}
+
+	for key, value := range values {
+		if err = d.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if diags := resourceRBDSnapshotRead(ctx, d, meta); diags.HasError() {
+		return nil, diagsToError(diags)
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("rbd snapshot %s: %w", snapSpec, api.ErrNotFound)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ResourceRBDSnapshotRollback rolls an image back to a snapshot on create, i.e. every time
+// image, snapshot or triggers change.
+func ResourceRBDSnapshotRollback() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRBDSnapshotRollbackCreate,
+		ReadContext:   resourceRBDSnapshotRollbackRead,
+		DeleteContext: resourceRBDSnapshotRollbackDelete,
+
+		Schema: map[string]*schema.Schema{
+			"image": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "image spec pool[/namespace]/image of the image to roll back",
+			},
+			"snapshot": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "snapshot to roll the image back to",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "arbitrary values, a change rolls the image back again",
+			},
+		},
+	}
+}
+
+func resourceRBDSnapshotRollbackCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	imageSpec := d.Get("image").(string)
+	snapName := d.Get("snapshot").(string)
+
+	if _, _, _, err := parseImageSpec(imageSpec); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] rolling back rbd image %s to snapshot %s", imageSpec, snapName)
+
+	if _, err := client.RollbackBlockImageSnapshot(imageSpec, snapName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s@%s", imageSpec, snapName))
+
+	return resourceRBDSnapshotRollbackRead(ctx, d, meta)
+}
+
+func resourceRBDSnapshotRollbackRead(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// a rollback has no state in ceph
+	return diag.Diagnostics{}
+}
+
+func resourceRBDSnapshotRollbackDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// nothing to undo in ceph, just forget the rollback
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,41 @@
+package service
+
+import "testing"
+
+func TestParseImageSpec(t *testing.T) {
+	cases := []struct {
+		spec          string
+		pool, ns, img string
+		wantErr       bool
+	}{
+		{spec: "a/b", pool: "a", img: "b"},
+		{spec: "a/b/c", pool: "a", ns: "b", img: "c"},
+		{spec: "rbd/tenant-a/vm-disk-1", pool: "rbd", ns: "tenant-a", img: "vm-disk-1"},
+		{spec: "a//c", wantErr: true},
+		{spec: "a", wantErr: true},
+		{spec: "", wantErr: true},
+		{spec: "/b", wantErr: true},
+		{spec: "a/", wantErr: true},
+		{spec: "a/b/c/d", wantErr: true},
+	}
+
+	for _, c := range cases {
+		pool, ns, img, err := parseImageSpec(c.spec)
+
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseImageSpec(%q) = %q, %q, %q, expected an error", c.spec, pool, ns, img)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseImageSpec(%q) unexpected error: %v", c.spec, err)
+			continue
+		}
+
+		if pool != c.pool || ns != c.ns || img != c.img {
+			t.Errorf("parseImageSpec(%q) = %q, %q, %q, expected %q, %q, %q", c.spec, pool, ns, img, c.pool, c.ns, c.img)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/chrisamti/terraform-provider-ceph-rest/cephrest/configuration"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DataSourceRBDTrash lists the images in the rbd trash of a pool (or of all pools).
+func DataSourceRBDTrash() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRBDTrashRead,
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "pool to list the trash of (all pools if not set)",
+			},
+			"images": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "rbd image id (needed for rbd trash restore)",
+						},
+						"pool_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name_space": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"img_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "how the image got into the trash (e.g. USER, MIGRATION)",
+						},
+						"deletion_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"deferment_end_time": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "time the image can be purged from",
+						},
+					},
+				},
+				Description: "images in the trash",
+			},
+		},
+	}
+}
+
+func dataSourceRBDTrashRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	_, trashList, err := client.ListBlockImageTrash(poolName)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	images := make([]interface{}, 0)
+
+	for _, pool := range trashList {
+		if pool.Status != rbdListStatusOK {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("trash of pool %s could not be listed completely", pool.PoolName),
+				Detail:   fmt.Sprintf("listing status %d, the trashed images of the pool may be missing or outdated", pool.Status),
+			})
+		}
+
+		for _, image := range pool.Value {
+			images = append(images, map[string]interface{}{
+				"id":                 image.ID,
+				"pool_name":          image.PoolName,
+				"name_space":         image.Namespace,
+				"img_name":           image.Name,
+				"source":             image.Source,
+				"deletion_time":      image.DeletionTime,
+				"deferment_end_time": image.DefermentEndTime,
+			})
+		}
+	}
+
+	if err = d.Set("images", images); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("trash|%s", poolName))
+
+	return diags
+}
+
+// ResourceRBDTrashPurge purges the expired images from the rbd trash of a pool on create,
+// i.e. every time pool_name or triggers change.
+func ResourceRBDTrashPurge() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRBDTrashPurgeCreate,
+		ReadContext:   resourceRBDTrashPurgeRead,
+		DeleteContext: resourceRBDTrashPurgeDelete,
+
+		Schema: map[string]*schema.Schema{
+			"pool_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "pool to purge the trash of",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "arbitrary values, a change purges the trash again",
+			},
+		},
+	}
+}
+
+func resourceRBDTrashPurgeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cephConf := meta.(*configuration.Ceph)
+	client := cephConf.Client
+
+	poolName := d.Get("pool_name").(string)
+
+	log.Printf("[DEBUG] purging rbd trash of pool %s", poolName)
+
+	if _, err := client.PurgeBlockImageTrash(poolName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(poolName)
+
+	return resourceRBDTrashPurgeRead(ctx, d, meta)
+}
+
+func resourceRBDTrashPurgeRead(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// a purge has no state in ceph
+	return diag.Diagnostics{}
+}
+
+func resourceRBDTrashPurgeDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// purged images can not be restored, just forget the purge
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -14,6 +14,9 @@ provider "ceph" {
   ceph_password = "XJEGy5yWrYxu758"
   ceph_server   = ["192.168.21.30", "192.168.21.31"]
   ceph_port     = 8443
+
+  # written to the ownership marker of created images (not passed by terraform on its own)
+  owner_workspace = terraform.workspace
 }
 
 resource "ceph_rbd" "ceph_rbd_test_1" {
@@ -21,4 +24,20 @@ resource "ceph_rbd" "ceph_rbd_test_1" {
   # name_space = ""
   img_name  = "terraform-created-1"
   size      = 1073741824
+  # alternatively: size_human = "1GiB"
 }
+
+# existing images can be imported by their image spec pool[/namespace]/image:
+# terraform import ceph_rbd.ceph_rbd_test_1 test-pool-1/terraform-created-1
+# images without ownership marker (e.g. created by hand) get the marker of this workspace on the
+# next apply, images marked by another workspace are only imported with CEPH_IGNORE_OWNERSHIP=true
+# (or provider ignore_ownership = true).
+
+resource "ceph_rbd_snapshot" "ceph_rbd_test_1_golden" {
+  pool_name    = ceph_rbd.ceph_rbd_test_1.pool_name
+  img_name     = ceph_rbd.ceph_rbd_test_1.img_name
+  snap_name    = "golden"
+  is_protected = true
+}
+
+# terraform import ceph_rbd_snapshot.ceph_rbd_test_1_golden test-pool-1/terraform-created-1@golden
@@ -6,6 +6,7 @@ require (
 	github.com/agext/levenshtein v1.2.3 // indirect
 	github.com/chrisamti/ceph-rest-client v0.0.0-20220119221129-ed798845889a
 	github.com/fatih/color v1.13.0 // indirect
+	github.com/go-resty/resty/v2 v2.7.0
 	github.com/google/go-cmp v0.5.7 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-version v1.4.0 // indirect